@@ -0,0 +1,25 @@
+package bitmaptable
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// WriteBase64 implements Bitmaptable.WriteBase64
+func (b *bitmaptable) WriteBase64(w io.Writer) error {
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if err := b.writeBinary(enc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadBase64 reads a table written by WriteBase64 from r. Like ReadFramed it
+// rejects tables whose data doesn't fit in a 32-bit length.
+func ReadBase64(r io.Reader) (Bitmaptable, error) {
+	b, err := readBinary(base64.NewDecoder(base64.StdEncoding, r))
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
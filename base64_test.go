@@ -0,0 +1,48 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBase64(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewAligned(10, 5)} {
+		b.Set(0, 0, true)
+		b.Set(4, 3, true)
+		b.Set(9, 4, true)
+
+		buf := new(bytes.Buffer)
+		if err := b.WriteBase64(buf); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(buf.String())
+		if err != nil {
+			t.Fatal("output must be valid base64", err)
+		}
+		if data, _ := b.Marshal(); !bytes.Equal(decoded, data) {
+			t.Fatal("output must decode to the marshaled table")
+		}
+
+		r, err := ReadBase64(buf)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !Equal(r, b) {
+			t.Fatal("wrong round trip")
+		}
+	}
+
+	if _, err := ReadBase64(strings.NewReader("AQAAAAAAAAAKAAAAAAAAAAUA")); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned", err)
+	}
+	if _, err := ReadBase64(strings.NewReader("")); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned", err)
+	}
+	huge := []byte{1, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0}
+	if _, err := ReadBase64(strings.NewReader(base64.StdEncoding.EncodeToString(huge))); !errors.Is(err, ErrIllegalData) {
+		t.Fatal("illegal data must be returned for a forged header", err)
+	}
+}
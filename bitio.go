@@ -0,0 +1,44 @@
+package bitmaptable
+
+// BitReader is a source of single bits, such as a custom bit-stream decoder.
+type BitReader interface {
+	ReadBit() (bool, error)
+}
+
+// BitWriter is a sink of single bits, such as a custom bit-stream encoder.
+type BitWriter interface {
+	WriteBit(bool) error
+}
+
+// NewFromBitReader creates a new Bitmaptable instance holding the first
+// rows*columns bits read from br, in row-major order. Running out of bits
+// returns ErrIllegalData.
+func NewFromBitReader(rows, columns int, br BitReader) (Bitmaptable, error) {
+	b := newNTS(rows, columns)
+	for i := 0; i < rows*columns; i++ {
+		v, err := br.ReadBit()
+		if err != nil {
+			return nil, readError(err)
+		}
+		if v {
+			b.bitmap.Set(i, true)
+		}
+	}
+	return b, nil
+}
+
+// WriteBits implements Bitmaptable.WriteBits
+func (b *bitmaptable) WriteBits(bw BitWriter) error {
+	if b.closed {
+		return ErrClosed
+	}
+	for row := 0; row < b.rows; row++ {
+		offset := row * b.stride
+		for column := 0; column < b.columns; column++ {
+			if err := bw.WriteBit(b.bitmap.Get(offset + column)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package bitmaptable
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// sliceBitReader reads the bits of a slice.
+type sliceBitReader []bool
+
+func (r *sliceBitReader) ReadBit() (bool, error) {
+	if len(*r) == 0 {
+		return false, io.EOF
+	}
+	v := (*r)[0]
+	*r = (*r)[1:]
+	return v, nil
+}
+
+func TestNewFromBitReader(t *testing.T) {
+	bits := make(sliceBitReader, 16)
+	for i := range bits {
+		bits[i] = i%3 == 0
+	}
+	br := bits
+	b, err := NewFromBitReader(3, 5, &br)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for i := 0; i < 15; i++ {
+		if v, _ := b.Get(i/5, i%5); v != (i%3 == 0) {
+			t.Fatal("wrong value at", i)
+		}
+	}
+	if len(br) != 1 {
+		t.Fatal("only rows*columns bits must be read", len(br))
+	}
+
+	br = bits[:14]
+	if _, err := NewFromBitReader(3, 5, &br); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned", err)
+	}
+	fail := errors.New("fail")
+	if _, err := NewFromBitReader(3, 5, failingBitReader{fail}); err != fail {
+		t.Fatal("reader error must be returned", err)
+	}
+}
+
+// failingBitReader fails every read.
+type failingBitReader struct {
+	err error
+}
+
+func (r failingBitReader) ReadBit() (bool, error) {
+	return false, r.err
+}
+
+// sliceBitWriter records the written bits, failing after limit bits if set.
+type sliceBitWriter struct {
+	bits  []bool
+	limit int
+}
+
+func (w *sliceBitWriter) WriteBit(v bool) error {
+	if w.limit > 0 && len(w.bits) == w.limit {
+		return io.ErrShortWrite
+	}
+	w.bits = append(w.bits, v)
+	return nil
+}
+
+func TestWriteBits(t *testing.T) {
+	for _, b := range []Bitmaptable{New(4, 6), NewTS(4, 6), NewAligned(4, 6)} {
+		for i := 0; i < 24; i += 5 {
+			b.Set(i/6, i%6, true)
+		}
+		w := new(sliceBitWriter)
+		if err := b.WriteBits(w); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if len(w.bits) != 24 {
+			t.Fatal("every cell must be written", len(w.bits))
+		}
+		for i, v := range w.bits {
+			if v != (i%5 == 0) {
+				t.Fatal("wrong bit at", i)
+			}
+		}
+
+		br := sliceBitReader(w.bits)
+		if r, _ := NewFromBitReader(4, 6, &br); !Equal(r, b) {
+			t.Fatal("wrong round trip")
+		}
+
+		if err := b.WriteBits(&sliceBitWriter{limit: 10}); err != io.ErrShortWrite {
+			t.Fatal("writer error must be returned", err)
+		}
+	}
+}
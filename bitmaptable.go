@@ -28,7 +28,12 @@
 package bitmaptable
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"math/rand"
 
 	"github.com/boljen/go-bitmap"
 )
@@ -37,15 +42,57 @@ import (
 var (
 	ErrIllegalIndex = errors.New("Bitmaptable: Illegal identifier or position")
 	ErrIllegalWidth = errors.New("Bitmaptable: Illegal value width, must be between 1 and 64")
+	ErrDimensions   = errors.New("Bitmaptable: Table dimensions don't match")
+	ErrNoTables     = errors.New("Bitmaptable: No tables provided")
+	ErrClosed       = errors.New("Bitmaptable: Table has been freed")
+	ErrIllegalData  = errors.New("Bitmaptable: Illegal serialized data")
+	ErrPattern      = errors.New("Bitmaptable: Pattern length must equal the amount of columns")
+	ErrStates       = errors.New("Bitmaptable: State names must be unique and at least one must be provided")
+	ErrUnknownState = errors.New("Bitmaptable: Unknown state")
+	ErrReadOnly     = errors.New("Bitmaptable: Table is read-only")
+	ErrIllegalSize  = errors.New("Bitmaptable: Illegal size, must be positive")
+	ErrOverflow     = errors.New("Bitmaptable: Value doesn't fit")
+	ErrPermutation  = errors.New("Bitmaptable: Not a permutation of the rows")
+
+	ErrUnsupportedVersion = errors.New("Bitmaptable: Unsupported serialization version")
+	ErrTooManyColumns     = errors.New("Bitmaptable: Amount of columns exceeds the maximum")
+	ErrImageSize          = errors.New("Bitmaptable: Table is too large for an image")
 )
 
+// Coord is the row and column tuple of a single cell.
+type Coord struct {
+	Row    int
+	Column int
+}
+
+// RowCount is the amount of set columns of a row.
+type RowCount struct {
+	Row   int
+	Count int
+}
+
+// Run is a range of consecutive rows for which a column holds the same value.
+type Run struct {
+	Value  bool
+	Start  int
+	Length int
+}
+
 // Bitmaptable is the basic bitmap table on which all other tables are built.
 // The bitmap table stores column-based bit information on a per-row basis.
 type Bitmaptable interface {
 	// Data returns the underlying data of the bitmap.
 	// If copy is true it will copy all the data into a new byteslice.
+	//
+	// Without a copy the data is shared with the table. For thread-safe
+	// tables this means it must not be accessed while the table may be
+	// written to concurrently; use SafeData instead.
 	Data(copy bool) []byte
 
+	// SafeData returns a copy of the underlying data of the bitmap. For
+	// thread-safe tables the copy is a consistent snapshot taken under lock.
+	SafeData() []byte
+
 	// Rows returns the amount of rows inside this bitmap table.
 	Rows() int
 
@@ -57,6 +104,358 @@ type Bitmaptable interface {
 
 	// Set sets the value for the provided row and column tuple.
 	Set(row int, column int, value bool) error
+
+	// WordAlignment returns the word size in bytes to which the capacity of
+	// the underlying data is rounded up, so that word sized loads over the
+	// data never read past its allocation. Tables on top of data provided by
+	// the caller report the largest power of two up to 8 that divides its
+	// capacity, which may be 1.
+	WordAlignment() int
+
+	// ValidIndex returns whether the provided row and column tuple lies within
+	// the table.
+	ValidIndex(row, column int) bool
+
+	// Free releases the underlying data of the bitmap table. The table has no
+	// rows or columns afterwards and operations return ErrClosed.
+	// Calling Free more than once has no effect.
+	Free()
+
+	// RangeRowsParallel calls fn for every row, with the rows partitioned
+	// across the provided amount of workers. The values slice is reused
+	// between calls by the same worker and must not be retained.
+	// fn must be safe for concurrent use and must not call methods of the table.
+	RangeRowsParallel(workers int, fn func(row int, values []bool))
+
+	// SampleSetBits returns up to n uniformly chosen coordinates of set bits,
+	// using reservoir sampling over a single pass of the table.
+	SampleSetBits(n int, rng *rand.Rand) []Coord
+
+	// Marshal serializes the bitmap table into the binary format read by
+	// Unmarshal.
+	Marshal() ([]byte, error)
+
+	// RowMajority returns whether more than half of the columns of the
+	// provided row are set. A tie returns false.
+	RowMajority(row int) (bool, error)
+
+	// FlipColumn inverts the provided column for every row.
+	FlipColumn(column int) error
+
+	// FlipRow inverts every column of the provided row.
+	FlipRow(row int) error
+
+	// PaddingBits returns the amount of bits in the underlying data that
+	// don't belong to any cell.
+	PaddingBits() int
+
+	// PaddingSet returns whether any of the padding bits is set.
+	PaddingSet() bool
+
+	// RowsWithinHamming returns the rows whose Hamming distance to the
+	// provided pattern is at most maxDist.
+	RowsWithinHamming(pattern []bool, maxDist int) ([]int, error)
+
+	// WriteCSV writes a "row,column" header followed by the coordinates of
+	// every set bit to w, one per line.
+	WriteCSV(w io.Writer) error
+
+	// Locate returns the position of the provided row and column tuple within
+	// Data: the index of the byte and the index of the bit within that byte,
+	// counting from the least significant bit.
+	Locate(row, column int) (byteIndex, bitIndex int, err error)
+
+	// ToggleMask inverts every cell that is set in the provided mask, which
+	// must have the same dimensions as the table.
+	ToggleMask(mask Bitmaptable) error
+
+	// EstimateCompressedSize returns the exact size in bytes of the header
+	// of Marshal followed by the run-length encoded data, without encoding
+	// it. It estimates how well the table compresses; real compressors may
+	// do better on irregular data.
+	EstimateCompressedSize() int
+
+	// ShiftColumn returns a single column table in which row i holds the value
+	// of the provided column at row i-k. A positive k lags the column, a
+	// negative k leads it, and rows shifted in from outside are false.
+	ShiftColumn(column, k int) (Bitmaptable, error)
+
+	// ColumnAsBitmap returns a new bitmap of Rows() bits holding the values
+	// of the provided column.
+	ColumnAsBitmap(column int) (bitmap.Bitmap, error)
+
+	// SortRows stably reorders the rows so that they are sorted according to
+	// less. Every comparison unpacks both rows, so sorting costs
+	// O(rows*log(rows)*columns) plus a copy of the table.
+	// less must not call methods of the table.
+	SortRows(less func(a, b []bool) bool)
+
+	// Stride returns the amount of bits each row takes in Data. It equals
+	// Columns unless the table was created with NewAligned.
+	Stride() int
+
+	// AddColumns appends n false columns to every row.
+	AddColumns(n int) error
+
+	// OverheadBytes returns the amount of bytes of the underlying data beyond
+	// the rows*columns bits the cells need, rounded up to a byte. It is 0 for
+	// tables created with New.
+	OverheadBytes() int
+
+	// EqualTo returns whether the table holds the same cells as other.
+	// It is equivalent to Equal.
+	EqualTo(other Bitmaptable) bool
+
+	// GoLiteral returns Go statements that recreate the table with
+	// NewFromCoords and assign it to varName, for use in test fixtures. The
+	// statements panic if NewFromCoords returns an error.
+	GoLiteral(varName string) string
+
+	// Count returns the amount of set cells.
+	Count() int
+
+	// Density returns the fraction of cells that are set, or 0 for a table
+	// without cells.
+	Density() float64
+
+	// BroadcastRowOr sets the columns that are set in the provided pattern
+	// for every row, keeping the cells that are already set.
+	BroadcastRowOr(pattern []bool) error
+
+	// FirstDifference returns the first cell in row-major order in which the
+	// table differs from other, which must have the same dimensions.
+	FirstDifference(other Bitmaptable) (row, column int, differ bool, err error)
+
+	// ClearColumn sets the provided column to false for every row.
+	ClearColumn(column int) error
+
+	// SelectColumns returns a new table holding only the provided columns,
+	// in the provided order.
+	SelectColumns(columns []int) (Bitmaptable, error)
+
+	// CountRegion returns the amount of set cells in the block of nRows by
+	// nCols cells starting at startRow and startCol.
+	CountRegion(startRow, startCol, nRows, nCols int) (int, error)
+
+	// WriteBase64 writes the table in the format of Marshal to w, encoded
+	// as standard base64.
+	WriteBase64(w io.Writer) error
+
+	// RangeSetBitsParallel calls fn for every set cell, with the data
+	// partitioned across the provided amount of workers.
+	// fn must be safe for concurrent use and must not call methods of the table.
+	RangeSetBitsParallel(workers int, fn func(row, column int))
+
+	// ColumnParity returns whether the provided column is set for an odd
+	// amount of rows.
+	ColumnParity(column int) (bool, error)
+
+	// TopRowsByPopcount returns the n rows with the most set columns, sorted
+	// by descending count. Ties are ordered by ascending row.
+	TopRowsByPopcount(n int) []RowCount
+
+	// ApplyChanges sets every cell in changes to its value. All coordinates
+	// are validated before any change is applied.
+	ApplyChanges(changes map[Coord]bool) error
+
+	// Cursor returns a cursor over the set cells of the table.
+	Cursor() *SetBitCursor
+
+	// OrRowsInto sets the cells of every row in dst at row rowMap[row],
+	// keeping the cells that are already set in dst. Both tables must have
+	// the same amount of columns and rowMap must hold a row of dst for every
+	// row of the table.
+	OrRowsInto(dst Bitmaptable, rowMap []int) error
+
+	// AnyInColumn returns whether the provided column is set for any row.
+	AnyInColumn(column int) (bool, error)
+
+	// AllInColumn returns whether the provided column is set for every row.
+	// It returns true for a table without rows.
+	AllInColumn(column int) (bool, error)
+
+	// DownsampleRowsAnd returns a table with a row for every group of k rows,
+	// in which a column is set only if it is set for every row of the group.
+	// The last group may hold fewer than k rows.
+	DownsampleRowsAnd(k int) (Bitmaptable, error)
+
+	// Get2Bit gets the value stored in columns startColumn (least
+	// significant bit) and startColumn+1 of the provided row.
+	Get2Bit(row, startColumn int) (uint8, error)
+
+	// Set2Bit stores a value from 0 to 3 in columns startColumn (least
+	// significant bit) and startColumn+1 of the provided row.
+	Set2Bit(row, startColumn int, v uint8) error
+
+	// ColumnCounts returns for every column the amount of rows in which it
+	// is set.
+	ColumnCounts() []int
+
+	// ColumnEntropies returns for every column the Shannon entropy in bits of
+	// its values, which is 0 for a column that is always or never set and 1
+	// for a column that is set for exactly half of the rows.
+	ColumnEntropies() []float64
+
+	// WriteFramed writes the table in the format of Marshal to w, prefixed
+	// with its length as a big-endian 32-bit integer, and returns the amount
+	// of bytes written.
+	WriteFramed(w io.Writer) (int, error)
+
+	// MostCommonRow returns the values of the row that occurs most often and
+	// the amount of rows holding them. Ties are resolved in favor of the
+	// pattern that occurs first. A table without rows returns nil and 0.
+	MostCommonRow() (pattern []bool, count int)
+
+	// RangeAll calls fn for every cell in row-major order, set or not, until
+	// fn returns false. fn must not call methods of the table.
+	RangeAll(fn func(row, column int, value bool) bool)
+
+	// ColumnCoOccurrence returns a columns by columns matrix in which entry
+	// [i][j] is the amount of rows with both column i and column j set. The
+	// diagonal holds the counts of ColumnCounts. It costs
+	// O(rows*columns^2) in the worst case.
+	ColumnCoOccurrence() [][]int
+
+	// ClearRowsBelow sets every cell of the rows before the provided row to
+	// false. row may equal Rows() to clear the whole table.
+	ClearRowsBelow(row int) error
+
+	// AppendTable appends the rows of other, which must have the same amount
+	// of columns, to the table.
+	AppendTable(other Bitmaptable) error
+
+	// ColumnsEqual returns whether columns a and b hold the same value for
+	// every row.
+	ColumnsEqual(a, b int) (bool, error)
+
+	// BitReversedPermutation returns the rows ordered by their bit-reversed
+	// index, using as many bits as the largest row needs. Indices that
+	// reverse to a row beyond the table are skipped, so the result is a
+	// permutation of the rows for any amount of rows.
+	BitReversedPermutation() []int
+
+	// PermuteRows reorders the rows so that row i holds what was row
+	// perm[i]. perm must hold every row exactly once.
+	PermuteRows(perm []int) error
+
+	// NonEmptyRowCount returns the amount of rows with at least one column
+	// set.
+	NonEmptyRowCount() int
+
+	// WritePNG writes the table to w as a black and white PNG image of
+	// Columns() by Rows() pixels, in which set cells are black. Tables with
+	// more than MaxImageDimension rows or columns return ErrImageSize.
+	WritePNG(w io.Writer) error
+
+	// RollingOrColumn returns for every row i whether the provided column is
+	// set for any of the rows i-window+1 through i. Windows reaching before
+	// the first row only consider the rows from the first row on.
+	RollingOrColumn(column, window int) ([]bool, error)
+
+	// MarshalColumnar serializes the table with the header of Marshal, but
+	// with the cells stored column by column: all rows of column 0, then all
+	// rows of column 1 and so on, packed without padding. Column c occupies
+	// bits c*Rows() through (c+1)*Rows()-1 of the data after the header.
+	MarshalColumnar() ([]byte, error)
+
+	// RowsMatchingAny returns the rows that hold exactly the values of any
+	// of the provided patterns, which must all have Columns() values.
+	RowsMatchingAny(patterns [][]bool) ([]int, error)
+
+	// NonZeroByteIndex returns for every byte of Data whether it holds a set
+	// bit, so that scans can skip zero regions. It is computed on every call.
+	NonZeroByteIndex() []bool
+
+	// IsColumnMonotone returns whether the provided column, once set, stays
+	// set for all following rows.
+	IsColumnMonotone(column int) (bool, error)
+
+	// FirstTrueInMonotoneColumn returns the first row for which the provided
+	// column is set, or -1 if it isn't set for any row, using a binary search
+	// that assumes the column is monotone as reported by IsColumnMonotone.
+	// For other columns the result is unspecified.
+	FirstTrueInMonotoneColumn(column int) (int, error)
+
+	// MarshalWithMeta serializes the table like Marshal, preceded by the
+	// provided metadata and its length as a big-endian 32-bit integer.
+	MarshalWithMeta(meta []byte) ([]byte, error)
+
+	// TrimTrailing removes the rows at the end of the table that have no
+	// columns set, reallocating the data, and returns the remaining amount
+	// of rows. Read-only and freed tables are left as is.
+	TrimTrailing() (trimmedRows int)
+
+	// LongestRunInColumn returns the first row and the length of the longest
+	// run of consecutive rows for which the provided column holds value. Of
+	// equally long runs the first is returned. If the column never holds
+	// value, startRow is -1 and length is 0.
+	LongestRunInColumn(column int, value bool) (startRow, length int, err error)
+
+	// SplitColumns returns a single column table for every column, holding
+	// its values for every row.
+	SplitColumns() []Bitmaptable
+
+	// VerticalAndCount returns the amount of rows after the first for which
+	// the provided column is set in both the row and the row before it.
+	VerticalAndCount(column int) (int, error)
+
+	// RotateColumn returns a single column table in which row (i+k) modulo
+	// Rows() holds the value of the provided column at row i. A positive k
+	// rotates toward higher rows, a negative k toward lower rows.
+	RotateColumn(column, k int) (Bitmaptable, error)
+
+	// RowParityColumn returns a single column table in which every row is
+	// set if the row of the table has an odd amount of columns set.
+	RowParityColumn() Bitmaptable
+
+	// SetBitsInRowRange returns the ascending rows from startRow up to but
+	// not including endRow for which the provided column is set.
+	SetBitsInRowRange(column, startRow, endRow int) ([]int, error)
+
+	// ConsumeUpdates applies every update received from ch until ch is closed
+	// or ctx is done. It returns the error of the first update that fails, or
+	// the error of ctx. Updates are applied one at a time, so a thread-safe
+	// table remains usable while updates are consumed.
+	ConsumeUpdates(ctx context.Context, ch <-chan Update) error
+
+	// ColumnRuns returns the values of the provided column as alternating
+	// runs, in ascending order of rows.
+	ColumnRuns(column int) ([]Run, error)
+
+	// ApproxCount estimates the amount of set cells from the set bits of
+	// sampleBytes bytes of the data, picked at random by rng. The estimate
+	// is unbiased. For cells that are set independently with density p its
+	// relative standard error is about sqrt((1-p)/(8*p*sampleBytes)), which
+	// is about 1% for 1000 bytes of a table with density 0.5.
+	// If sampleBytes covers the data, the exact count is returned.
+	ApproxCount(sampleBytes int, rng *rand.Rand) int
+
+	// ChangesSince returns the cells in row-major order that differ from
+	// snap, a snapshot of the data returned by SafeData or Data(true) for the
+	// current dimensions.
+	ChangesSince(snap []byte) ([]Coord, error)
+
+	// WriteBits writes every cell to bw in row-major order and returns the
+	// first error of bw.
+	WriteBits(bw BitWriter) error
+
+	// CountZeros returns the amount of cells that aren't set. Padding bits
+	// aren't cells and aren't counted.
+	CountZeros() int
+
+	// FindRow returns the first row for whose values pred returns true, or -1
+	// if there is none. The values are reused between calls of pred, which
+	// must not call methods of the table.
+	FindRow(pred func(values []bool) bool) (int, error)
+
+	// Pack16 returns the data of the table in an array that can live on the
+	// stack, with the unused bytes zero. It returns false if the data needs
+	// more than 16 bytes.
+	Pack16() ([16]byte, bool)
+
+	// EmptyColumns returns the sorted indices of the columns that aren't set
+	// for any row.
+	EmptyColumns() []int
 }
 
 // New creates a new Bitmaptable instance.
@@ -71,18 +470,147 @@ func NewTS(rows, columns int) Bitmaptable {
 	return newTS(rows, columns)
 }
 
+// IsThreadSafe returns whether the provided table is safe for concurrent use.
+func IsThreadSafe(b Bitmaptable) bool {
+	_, ok := b.(interface{ threadSafe() })
+	return ok
+}
+
+// NewAutoGrow creates a new Bitmaptable instance without rows, to which Set
+// adds rows as needed instead of returning ErrIllegalIndex. The rows in between
+// are false. Beware that a single large row index allocates memory for every
+// row up to it.
+func NewAutoGrow(columns int) Bitmaptable {
+	b := newNTS(0, columns)
+	b.autoGrow = true
+	return b
+}
+
+// NewBounded creates a new Bitmaptable instance like New, but returns
+// ErrTooManyColumns instead of allocating when columns exceeds maxColumns.
+// It guards against huge allocations for dimensions supplied by users.
+func NewBounded(rows, columns, maxColumns int) (Bitmaptable, error) {
+	if err := checkMaxColumns(columns, maxColumns); err != nil {
+		return nil, err
+	}
+	return newNTS(rows, columns), nil
+}
+
+func checkMaxColumns(columns, maxColumns int) error {
+	if columns > maxColumns {
+		return fmt.Errorf("%w: got %d columns, maximum is %d", ErrTooManyColumns, columns, maxColumns)
+	}
+	return nil
+}
+
+// NewFromBitIndices creates a new Bitmaptable instance with the provided bits
+// set. Each bit is a flat row*columns+column index into the table.
+func NewFromBitIndices(rows, columns int, bits []int) (Bitmaptable, error) {
+	b := newNTS(rows, columns)
+	for _, i := range bits {
+		if i < 0 || i >= rows*columns {
+			return nil, ErrIllegalIndex
+		}
+		b.bitmap.Set(i, true)
+	}
+	return b, nil
+}
+
+// NewFromCoords creates a new Bitmaptable instance with the cells at the
+// provided coordinates set.
+func NewFromCoords(rows, columns int, coords []Coord) (Bitmaptable, error) {
+	b := newNTS(rows, columns)
+	for _, c := range coords {
+		if err := b.Set(c.Row, c.Column, true); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// NewFromData creates a new Bitmaptable instance on top of the provided data,
+// which is used without copying and must hold at least rows*columns bits. The
+// padding bits in the last byte used are cleared. Data that is too short
+// returns an error wrapping ErrIllegalData.
+func NewFromData(rows, columns int, data []byte) (Bitmaptable, error) {
+	return newFromData(rows, columns, data, false)
+}
+
+// NewFromDataStrict is like NewFromData, but also rejects data that holds
+// more bytes than rows*columns bits need, which often indicates that the
+// data doesn't belong to a table of these dimensions.
+func NewFromDataStrict(rows, columns int, data []byte) (Bitmaptable, error) {
+	return newFromData(rows, columns, data, true)
+}
+
+func newFromData(rows, columns int, data []byte, strict bool) (Bitmaptable, error) {
+	need := (rows*columns + 7) / 8
+	if len(data) < need || (strict && len(data) != need) {
+		return nil, fmt.Errorf("%w: need %d bytes, got %d", ErrIllegalData, need, len(data))
+	}
+	// Only the bytes the cells need are used, so that the length of the data
+	// always follows from the dimensions. The capacity is limited as well, so
+	// that growing the table never writes to the bytes of the caller beyond.
+	b := &bitmaptable{
+		rows:    rows,
+		columns: columns,
+		stride:  columns,
+		bitmap:  data[:need:need],
+		surplus: len(data) - need,
+	}
+	b.normalize()
+	return b, nil
+}
+
+// wordSize is the alignment in bytes of the capacity of the underlying data.
+const wordSize = 8
+
+// NewAligned creates a new Bitmaptable instance in which every row is padded
+// to a multiple of 8 bits, so that rows start on a byte boundary and
+// AddColumns doesn't need to move any data as long as the new columns fit in
+// the padding. This trades up to 7 bits of memory per row for cheaper growth.
+func NewAligned(rows, columns int) Bitmaptable {
+	return newStrided(rows, columns, alignStride(columns), true)
+}
+
 func newNTS(rows, columns int) *bitmaptable {
+	return newStrided(rows, columns, columns, false)
+}
+
+func newStrided(rows, columns, stride int, aligned bool) *bitmaptable {
 	return &bitmaptable{
 		rows:    rows,
 		columns: columns,
-		bitmap:  bitmap.New(columns * rows),
+		stride:  stride,
+		aligned: aligned,
+		bitmap:  newAligned(stride * rows),
 	}
 }
 
+// alignStride rounds the amount of columns up to a multiple of 8.
+func alignStride(columns int) int {
+	return (columns + 7) / 8 * 8
+}
+
+// newAligned allocates a bitmap of l bits whose capacity is a multiple of the
+// word size.
+func newAligned(l int) bitmap.Bitmap {
+	n := (l + 7) / 8
+	return make(bitmap.Bitmap, n, (n+wordSize-1)/wordSize*wordSize)
+}
+
 type bitmaptable struct {
 	rows    int           // Amount of rows.
 	columns int           // Amount of columns per row.
+	stride  int           // Amount of bits per row.
+	aligned bool          // Whether the stride is kept byte aligned.
 	bitmap  bitmap.Bitmap // The actual bitmap
+	closed  bool          // Whether the table has been freed.
+	surplus int           // Unused bytes of the data provided to NewFromData.
+
+	readOnly bool         // Whether mutations are rejected.
+	autoGrow bool         // Whether Set adds rows as needed.
+	release  func() error // Releases the data when the table is freed.
 }
 
 // Rows implements Bitmaptable.Rows
@@ -95,24 +623,297 @@ func (b *bitmaptable) Columns() int {
 	return b.columns
 }
 
+// Stride implements Bitmaptable.Stride
+func (b *bitmaptable) Stride() int {
+	return b.stride
+}
+
 // Data implements Bitmaptable.Data
 func (b *bitmaptable) Data(c bool) []byte {
 	return b.bitmap.Data(c)
 }
 
+// Pack16 implements Bitmaptable.Pack16
+func (b *bitmaptable) Pack16() ([16]byte, bool) {
+	var data [16]byte
+	n := (b.rows*b.stride + 7) / 8
+	if n > len(data) {
+		return data, false
+	}
+	copy(data[:], b.bitmap[:n])
+	return data, true
+}
+
+// NonZeroByteIndex implements Bitmaptable.NonZeroByteIndex
+func (b *bitmaptable) NonZeroByteIndex() []bool {
+	index := make([]bool, len(b.bitmap))
+	for i, v := range b.bitmap {
+		index[i] = v != 0
+	}
+	return index
+}
+
+// SafeData implements Bitmaptable.SafeData
+func (b *bitmaptable) SafeData() []byte {
+	return b.bitmap.Data(true)
+}
+
+// WordAlignment implements Bitmaptable.WordAlignment
+func (b *bitmaptable) WordAlignment() int {
+	n := wordSize
+	for cap(b.bitmap)%n != 0 {
+		n /= 2
+	}
+	return n
+}
+
+// PaddingBits implements Bitmaptable.PaddingBits
+func (b *bitmaptable) PaddingBits() int {
+	return len(b.bitmap)*8 - b.rows*b.columns
+}
+
+// OverheadBytes implements Bitmaptable.OverheadBytes
+func (b *bitmaptable) OverheadBytes() int {
+	return len(b.bitmap) + b.surplus - (b.rows*b.columns+7)/8
+}
+
+// PaddingSet implements Bitmaptable.PaddingSet
+func (b *bitmaptable) PaddingSet() bool {
+	if b.stride != b.columns {
+		for row := 0; row < b.rows; row++ {
+			if b.countRange(row*b.stride+b.columns, (row+1)*b.stride) != 0 {
+				return true
+			}
+		}
+	}
+	return b.countRange(b.rows*b.stride, len(b.bitmap)*8) != 0
+}
+
+// Locate implements Bitmaptable.Locate
+func (b *bitmaptable) Locate(row, column int) (int, int, error) {
+	if err := b.check(row, column); err != nil {
+		return 0, 0, err
+	}
+	i := row*b.stride + column
+	return i / 8, i % 8, nil
+}
+
+// Free implements Bitmaptable.Free
+func (b *bitmaptable) Free() {
+	if b.release != nil {
+		b.release()
+		b.release = nil
+	}
+	b.rows = 0
+	b.columns = 0
+	b.stride = 0
+	b.bitmap = nil
+	b.surplus = 0
+	b.closed = true
+}
+
+// check validates the provided row and column tuple.
+func (b *bitmaptable) check(row int, column int) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if !b.ValidIndex(row, column) {
+		return ErrIllegalIndex
+	}
+	return nil
+}
+
+// ValidIndex implements Bitmaptable.ValidIndex
+func (b *bitmaptable) ValidIndex(row, column int) bool {
+	return row >= 0 && column >= 0 && row < b.rows && column < b.columns
+}
+
+// writable returns an error if the table can't be mutated.
+func (b *bitmaptable) writable() error {
+	if b.closed {
+		return ErrClosed
+	}
+	if b.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// checkColumn validates the provided column.
+func (b *bitmaptable) checkColumn(column int) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if column < 0 || column >= b.columns {
+		return ErrIllegalIndex
+	}
+	return nil
+}
+
+// checkRow validates the provided row.
+func (b *bitmaptable) checkRow(row int) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if row < 0 || row >= b.rows {
+		return ErrIllegalIndex
+	}
+	return nil
+}
+
 // Get implements Bitmaptable.Get
 func (b *bitmaptable) Get(row int, column int) (bool, error) {
-	if column >= b.columns || row >= b.rows {
-		return false, ErrIllegalIndex
+	if err := b.check(row, column); err != nil {
+		return false, err
 	}
-	return b.bitmap.Get(row*b.columns + column), nil
+	return b.bitmap.Get(row*b.stride + column), nil
 }
 
 // Set implements Bitmaptable.Set
 func (b *bitmaptable) Set(row int, column int, value bool) error {
-	if column >= int(b.columns) || row >= int(b.rows) {
-		return ErrIllegalIndex
+	if err := b.writable(); err != nil {
+		return err
 	}
-	b.bitmap.Set(row*b.columns+column, value)
+	if b.autoGrow && row >= b.rows && column >= 0 && column < b.columns {
+		// The bits of row+1 rows, rounded up to bytes, must fit in an int.
+		if row >= (int(maxInt)-7)/b.stride {
+			return ErrIllegalIndex
+		}
+		b.grow(row + 1)
+	}
+	if err := b.check(row, column); err != nil {
+		return err
+	}
+	b.bitmap.Set(row*b.stride+column, value)
 	return nil
 }
+
+// eachSetBit calls fn with the coordinates of every set bit in row-major
+// order, skipping padding. Iteration stops when fn returns false.
+func (b *bitmaptable) eachSetBit(fn func(row, column int) bool) {
+	b.eachSetBitIn(0, len(b.bitmap), fn)
+}
+
+// eachSetBitIn is eachSetBit restricted to the bytes [start, end) of the data.
+func (b *bitmaptable) eachSetBitIn(start, end int, fn func(row, column int) bool) {
+	n := b.rows * b.stride
+	for i := start; i < end; i++ {
+		v := b.bitmap[i]
+		if v == 0 {
+			continue
+		}
+		for bit := 0; bit < 8; bit++ {
+			if v&(1<<uint(bit)) == 0 {
+				continue
+			}
+			index := i*8 + bit
+			if index >= n {
+				return
+			}
+			if column := index % b.stride; column < b.columns && !fn(index/b.stride, column) {
+				return
+			}
+		}
+	}
+}
+
+// countRange returns the amount of set bits in the flat range [start, end).
+func (b *bitmaptable) countRange(start, end int) int {
+	count := 0
+	for start < end && start%8 != 0 {
+		if b.bitmap.Get(start) {
+			count++
+		}
+		start++
+	}
+	for ; start+8 <= end; start += 8 {
+		count += bits.OnesCount8(b.bitmap[start/8])
+	}
+	for ; start < end; start++ {
+		if b.bitmap.Get(start) {
+			count++
+		}
+	}
+	return count
+}
+
+// anyRange returns whether any bit in the flat range [start, end) is set,
+// testing whole bytes where possible.
+func (b *bitmaptable) anyRange(start, end int) bool {
+	for ; start < end && start%8 != 0; start++ {
+		if b.bitmap.Get(start) {
+			return true
+		}
+	}
+	for ; start+8 <= end; start += 8 {
+		if b.bitmap[start/8] != 0 {
+			return true
+		}
+	}
+	for ; start < end; start++ {
+		if b.bitmap.Get(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize clears the padding bits.
+func (b *bitmaptable) normalize() {
+	if len(b.bitmap) > 0 {
+		b.bitmap[len(b.bitmap)-1] &= lastByteMask(b.rows * b.stride)
+	}
+	if b.stride != b.columns {
+		for row := 0; row < b.rows; row++ {
+			for i := row*b.stride + b.columns; i < (row+1)*b.stride; i++ {
+				b.bitmap.Set(i, false)
+			}
+		}
+	}
+}
+
+// flipRange inverts the bits in the flat range [start, end).
+func (b *bitmaptable) flipRange(start, end int) {
+	for ; start < end && start%8 != 0; start++ {
+		b.bitmap[start/8] ^= 1 << uint(start%8)
+	}
+	for ; start+8 <= end; start += 8 {
+		b.bitmap[start/8] = ^b.bitmap[start/8]
+	}
+	for ; start < end; start++ {
+		b.bitmap[start/8] ^= 1 << uint(start%8)
+	}
+}
+
+// copyRow copies the bits of row src of the bitmap from to row dst of the
+// table. Both must have the same stride.
+func (b *bitmaptable) copyRow(dst int, from bitmap.Bitmap, src int) {
+	d, s := dst*b.stride, src*b.stride
+	for column := 0; column < b.columns; column++ {
+		b.bitmap.Set(d+column, from.Get(s+column))
+	}
+}
+
+// grow increases the amount of rows, doubling the capacity of the data when it
+// runs out so that repeated growth is amortized.
+func (b *bitmaptable) grow(rows int) {
+	b.normalize()
+	n := (rows*b.stride + 7) / 8
+	if n > cap(b.bitmap) {
+		c := 2 * cap(b.bitmap)
+		if c < n {
+			c = n
+		}
+		data := make(bitmap.Bitmap, n, (c+wordSize-1)/wordSize*wordSize)
+		copy(data, b.bitmap)
+		b.bitmap = data
+		b.surplus = 0
+	} else {
+		old := len(b.bitmap)
+		b.bitmap = b.bitmap[:n]
+		for i := old; i < n; i++ {
+			b.bitmap[i] = 0
+		}
+	}
+	b.rows = rows
+}
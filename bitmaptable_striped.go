@@ -0,0 +1,86 @@
+package bitmaptable
+
+import "sync"
+
+// LockMode configures the locking granularity of a thread-safe table.
+type LockMode struct {
+	stripes int
+}
+
+// WholeTable guards the entire table with a single lock, like NewTS.
+var WholeTable = LockMode{}
+
+// PerRowStriped guards cells with n striped locks so that Get and Set on
+// different rows rarely contend. Cells are assigned to a stripe by the byte
+// they are stored in, so rows that share a byte also share a stripe.
+//
+// Operations other than Get and Set lock the table as a whole, excluding all
+// stripes at once.
+func PerRowStriped(n int) LockMode {
+	if n < 1 {
+		n = 1
+	}
+	return LockMode{stripes: n}
+}
+
+// NewTSWithLocking creates a new thread-safe Bitmaptable instance using the
+// provided locking granularity.
+func NewTSWithLocking(rows, columns int, mode LockMode) Bitmaptable {
+	if mode.stripes == 0 {
+		return newTS(rows, columns)
+	}
+	return newStriped(newNTS(rows, columns), mode.stripes)
+}
+
+// striped is a thread-safe table with striped locks for cell access.
+//
+// Cell operations hold the read side of rw plus the lock of their stripe,
+// all other operations are inherited from ts and hold the write side of rw.
+type striped struct {
+	*ts
+	rw      *sync.RWMutex
+	stripes []sync.Mutex
+}
+
+func newStriped(b *bitmaptable, n int) *striped {
+	rw := new(sync.RWMutex)
+	return &striped{
+		ts:      &ts{mu: rw, b: b},
+		rw:      rw,
+		stripes: make([]sync.Mutex, n),
+	}
+}
+
+// stripe returns the lock guarding the provided cell, which must be valid.
+func (s *striped) stripe(row, column int) *sync.Mutex {
+	i := (row*s.b.stride + column) / 8
+	return &s.stripes[i%len(s.stripes)]
+}
+
+// Get implements Bitmaptable.Get
+func (s *striped) Get(row int, column int) (bool, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	if err := s.b.check(row, column); err != nil {
+		return false, err
+	}
+	mu := s.stripe(row, column)
+	mu.Lock()
+	v, err := s.b.Get(row, column)
+	mu.Unlock()
+	return v, err
+}
+
+// Set implements Bitmaptable.Set
+func (s *striped) Set(row int, column int, value bool) error {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	if err := s.b.check(row, column); err != nil {
+		return err
+	}
+	mu := s.stripe(row, column)
+	mu.Lock()
+	err := s.b.Set(row, column, value)
+	mu.Unlock()
+	return err
+}
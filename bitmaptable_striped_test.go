@@ -0,0 +1,86 @@
+package bitmaptable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewTSWithLocking(t *testing.T) {
+	if _, ok := NewTSWithLocking(10, 5, WholeTable).(*ts); !ok {
+		t.Fatal("whole table locking must return the thread-safe table")
+	}
+	s, ok := NewTSWithLocking(10, 5, PerRowStriped(4)).(*striped)
+	if !ok || len(s.stripes) != 4 {
+		t.Fatal("striped locking must return a striped table")
+	}
+	if s := NewTSWithLocking(10, 5, PerRowStriped(0)).(*striped); len(s.stripes) != 1 {
+		t.Fatal("at least one stripe must be used")
+	}
+}
+
+func TestStripedGetSet(t *testing.T) {
+	b := NewTSWithLocking(1000, 12, PerRowStriped(8))
+	if err := b.Set(1001, 0, true); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if err := b.Set(5, 11, true); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if v, err := b.Get(5, 11); err != nil || !v {
+		t.Fatal("wrong return")
+	}
+	if _, err := b.Get(-1, 0); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if b.Count() != 1 {
+		t.Fatal("table wide operations must see the data")
+	}
+	b.Free()
+	if _, err := b.Get(5, 11); err != ErrClosed {
+		t.Fatal("closed error must be returned")
+	}
+}
+
+func TestStripedConcurrency(t *testing.T) {
+	for _, mode := range []LockMode{WholeTable, PerRowStriped(16)} {
+		b := NewTSWithLocking(100, 5, mode)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for row := w; row < 100; row += 8 {
+					for column := 0; column < 5; column++ {
+						b.Set(row, column, true)
+						b.Get((row+1)%100, column)
+					}
+				}
+				b.Count()
+			}(w)
+		}
+		wg.Wait()
+		if b.Count() != 500 {
+			t.Fatal("lost updates", b.Count())
+		}
+	}
+}
+
+func benchmarkLocking(bm *testing.B, mode LockMode) {
+	b := NewTSWithLocking(1<<16, 5, mode)
+	bm.RunParallel(func(pb *testing.PB) {
+		row := 0
+		for pb.Next() {
+			b.Set(row, row%5, true)
+			b.Get(row, row%5)
+			row = (row + 7919) % (1 << 16)
+		}
+	})
+}
+
+func BenchmarkWholeTableLocking(b *testing.B) {
+	benchmarkLocking(b, WholeTable)
+}
+
+func BenchmarkPerRowStripedLocking(b *testing.B) {
+	benchmarkLocking(b, PerRowStriped(64))
+}
@@ -1,6 +1,10 @@
 package bitmaptable
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
 
 func TestNew(t *testing.T) {
 	New(10, 5)
@@ -59,3 +63,324 @@ func TestBitmaptableGetSet(t *testing.T) {
 		t.Fatal("illegal index")
 	}
 }
+
+func TestNewFromBitIndices(t *testing.T) {
+	b, err := NewFromBitIndices(4, 3, []int{0, 4, 11, 4})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for i := 0; i < 12; i++ {
+		v, _ := b.Get(i/3, i%3)
+		if v != (i == 0 || i == 4 || i == 11) {
+			t.Fatal("wrong value at bit", i)
+		}
+	}
+
+	if _, err := NewFromBitIndices(4, 3, []int{1, 12}); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if _, err := NewFromBitIndices(4, 3, []int{-1}); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+}
+
+func TestWordAlignment(t *testing.T) {
+	for _, dim := range [][2]int{{1, 1}, {10, 5}, {8, 8}, {3, 33}, {1000, 12}} {
+		b := newNTS(dim[0], dim[1])
+		if b.WordAlignment() != 8 {
+			t.Fatal("wrong word alignment")
+		}
+		if cap(b.bitmap)%b.WordAlignment() != 0 || cap(b.bitmap) < len(b.bitmap) {
+			t.Fatal("capacity not aligned", dim, cap(b.bitmap))
+		}
+		if len(b.bitmap) != (dim[0]*dim[1]+7)/8 {
+			t.Fatal("wrong data length", dim, len(b.bitmap))
+		}
+	}
+
+	for size, alignment := range map[int]int{1: 1, 3: 1, 6: 2, 12: 4, 16: 8} {
+		b, _ := NewFromData(size*8, 1, make([]byte, size, 32))
+		if b.WordAlignment() != alignment {
+			t.Fatal("wrong word alignment for caller data", size, b.WordAlignment())
+		}
+	}
+}
+
+func TestFree(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		b.Set(1, 1, true)
+		b.Free()
+		b.Free()
+		if b.Rows() != 0 || b.Columns() != 0 || len(b.Data(false)) != 0 {
+			t.Fatal("table wasn't freed")
+		}
+		if _, err := b.Get(1, 1); err != ErrClosed {
+			t.Fatal("closed error must be returned")
+		}
+		if err := b.Set(1, 1, true); err != ErrClosed {
+			t.Fatal("closed error must be returned")
+		}
+	}
+}
+
+func TestPadding(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		if b.PaddingBits() != 6 || b.PaddingSet() {
+			t.Fatal("wrong padding")
+		}
+		b.Set(9, 4, true)
+		if b.PaddingSet() {
+			t.Fatal("padding mustn't be set")
+		}
+		b.Data(false)[6] |= 0x80
+		if !b.PaddingSet() {
+			t.Fatal("padding must be set")
+		}
+	}
+
+	b := New(8, 2)
+	if b.PaddingBits() != 0 || b.PaddingSet() {
+		t.Fatal("wrong padding")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		for _, c := range [][4]int{{0, 0, 0, 0}, {1, 2, 0, 7}, {1, 3, 1, 0}, {6, 2, 4, 0}, {9, 4, 6, 1}} {
+			byteIndex, bitIndex, err := b.Locate(c[0], c[1])
+			if err != nil || byteIndex != c[2] || bitIndex != c[3] {
+				t.Fatal("wrong location for", c[0], c[1], byteIndex, bitIndex)
+			}
+			b.Set(c[0], c[1], true)
+			if b.Data(false)[byteIndex]&(1<<uint(bitIndex)) == 0 {
+				t.Fatal("location doesn't match data")
+			}
+		}
+		if _, _, err := b.Locate(10, 0); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestValidIndex(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		for _, c := range []Coord{{0, 0}, {9, 4}, {5, 2}} {
+			if !b.ValidIndex(c.Row, c.Column) {
+				t.Fatal("index must be valid", c)
+			}
+		}
+		for _, c := range []Coord{{-1, 0}, {0, -1}, {10, 0}, {0, 5}, {10, 5}} {
+			if b.ValidIndex(c.Row, c.Column) {
+				t.Fatal("index must be invalid", c)
+			}
+			if _, err := b.Get(c.Row, c.Column); err != ErrIllegalIndex {
+				t.Fatal("illegal index must be returned", c)
+			}
+			if err := b.Set(c.Row, c.Column, true); err != ErrIllegalIndex {
+				t.Fatal("illegal index must be returned", c)
+			}
+		}
+	}
+}
+
+func TestNewFromData(t *testing.T) {
+	data := make([]byte, 7)
+	data[1] = 0x01
+	b, err := NewFromData(10, 5, data)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if v, _ := b.Get(1, 3); !v {
+		t.Fatal("data must be used as is")
+	}
+	b.Set(0, 0, true)
+	if data[0] != 1 {
+		t.Fatal("data mustn't be copied")
+	}
+	data[6] = 0xff
+	if b, _ := NewFromData(10, 5, data); b.PaddingSet() || data[6] != 0x03 {
+		t.Fatal("padding bits of the data must be cleared", data[6])
+	}
+	_, err = NewFromData(10, 5, make([]byte, 6))
+	if !errors.Is(err, ErrIllegalData) || err.Error() != ErrIllegalData.Error()+": need 7 bytes, got 6" {
+		t.Fatal("illegal data must be returned", err)
+	}
+	long, err := NewFromData(10, 5, make([]byte, 8))
+	if err != nil {
+		t.Fatal("longer data must be accepted", err)
+	}
+	if len(long.Data(false)) != 7 || long.OverheadBytes() != 1 {
+		t.Fatal("only the needed bytes must be used", len(long.Data(false)), long.OverheadBytes())
+	}
+	mask := New(10, 5)
+	mask.Set(9, 4, true)
+	if err := long.ToggleMask(mask); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if r, err := OrPadded(long, mask); err != nil || r.Count() != 1 {
+		t.Fatal("longer data must combine like any table", err)
+	}
+}
+
+func TestNewFromDataStrict(t *testing.T) {
+	if _, err := NewFromDataStrict(10, 5, make([]byte, 7)); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if _, err := NewFromDataStrict(10, 5, make([]byte, 6)); !errors.Is(err, ErrIllegalData) {
+		t.Fatal("illegal data must be returned for short data", err)
+	}
+	_, err := NewFromDataStrict(10, 5, make([]byte, 8))
+	if !errors.Is(err, ErrIllegalData) || err.Error() != ErrIllegalData.Error()+": need 7 bytes, got 8" {
+		t.Fatal("illegal data must be returned for long data", err)
+	}
+}
+
+func TestOverheadBytes(t *testing.T) {
+	for _, dim := range [][2]int{{10, 5}, {8, 8}, {1, 1}, {0, 3}} {
+		if o := New(dim[0], dim[1]).OverheadBytes(); o != 0 {
+			t.Fatal("default constructor mustn't have overhead", dim, o)
+		}
+		if o := NewTS(dim[0], dim[1]).OverheadBytes(); o != 0 {
+			t.Fatal("default constructor mustn't have overhead", dim, o)
+		}
+	}
+	b, _ := NewFromData(10, 5, make([]byte, 10))
+	if b.OverheadBytes() != 3 {
+		t.Fatal("wrong overhead")
+	}
+	if NewAligned(10, 3).OverheadBytes() != 6 {
+		t.Fatal("wrong overhead")
+	}
+}
+
+func TestIsThreadSafe(t *testing.T) {
+	for _, b := range []Bitmaptable{
+		NewTS(10, 5),
+		NewTSWithLocking(10, 5, WholeTable),
+		NewTSWithLocking(10, 5, PerRowStriped(4)),
+		NewTimestamped(10, 5, nil),
+	} {
+		if !IsThreadSafe(b) {
+			t.Fatal("table must be thread-safe")
+		}
+	}
+	for _, b := range []Bitmaptable{New(10, 5), NewAligned(10, 5)} {
+		if IsThreadSafe(b) {
+			t.Fatal("table mustn't be thread-safe")
+		}
+	}
+}
+
+func TestNewAutoGrow(t *testing.T) {
+	b := NewAutoGrow(3)
+	if b.Rows() != 0 || b.Columns() != 3 {
+		t.Fatal("wrong configuration")
+	}
+	if err := b.Set(0, 1, true); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := b.Set(1000, 2, true); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if b.Rows() != 1001 {
+		t.Fatal("wrong amount of rows", b.Rows())
+	}
+	if b.Count() != 2 {
+		t.Fatal("rows in between must be false")
+	}
+	if v, _ := b.Get(1000, 2); !v {
+		t.Fatal("high row must be set")
+	}
+	if v, _ := b.Get(0, 1); !v {
+		t.Fatal("existing rows must be kept")
+	}
+
+	for row := 0; row < 3000; row++ {
+		b.Set(row, row%3, true)
+	}
+	if b.Rows() != 3000 || b.Count() != 3002 {
+		t.Fatal("wrong growth", b.Rows(), b.Count())
+	}
+	if err := b.Set(5000, 3, true); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if err := b.Set(-1, 0, true); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	for _, row := range []int{int(maxInt), 1 << 62} {
+		if err := b.Set(row, 0, true); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned for rows that overflow", row)
+		}
+	}
+	if b.Rows() != 3000 {
+		t.Fatal("invalid sets mustn't grow the table")
+	}
+	if _, err := b.Get(3000, 0); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+}
+
+func TestNewBounded(t *testing.T) {
+	for _, columns := range []int{15, 16} {
+		b, err := NewBounded(4, columns, 16)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if b.Rows() != 4 || b.Columns() != columns {
+			t.Fatal("wrong configuration")
+		}
+	}
+	if _, err := NewBounded(4, 17, 16); !errors.Is(err, ErrTooManyColumns) {
+		t.Fatal("too many columns must be returned", err)
+	}
+}
+
+func TestNonZeroByteIndex(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 7), NewTS(10, 7), NewAligned(10, 7)} {
+		b.Set(0, 2, true)
+		b.Set(5, 6, true)
+		b.Set(9, 0, true)
+		b.Set(9, 1, true)
+		b.Set(9, 1, false)
+		index := b.NonZeroByteIndex()
+		data := b.Data(false)
+		if len(index) != len(data) {
+			t.Fatal("wrong index length", len(index))
+		}
+		nonZero := 0
+		for i, v := range index {
+			if v != (data[i] != 0) {
+				t.Fatal("wrong index at", i)
+			}
+			if v {
+				nonZero++
+			}
+		}
+		if nonZero != 3 {
+			t.Fatal("wrong amount of non-zero bytes", nonZero)
+		}
+	}
+}
+
+func TestPack16(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 12), NewTS(10, 12), NewAligned(8, 12)} {
+		b.Set(0, 0, true)
+		b.Set(b.Rows()-1, 11, true)
+		data, ok := b.Pack16()
+		if !ok {
+			t.Fatal("table must fit")
+		}
+		n := (b.Rows()*b.Stride() + 7) / 8
+		if !bytes.Equal(data[:n], b.Data(false)[:n]) || !bytes.Equal(data[n:], make([]byte, 16-n)) {
+			t.Fatal("wrong packed data", data)
+		}
+	}
+	b := New(11, 12)
+	b.Set(10, 11, true)
+	if data, ok := b.Pack16(); ok || data != [16]byte{} {
+		t.Fatal("table mustn't fit")
+	}
+	if _, ok := New(0, 0).Pack16(); !ok {
+		t.Fatal("an empty table must fit")
+	}
+}
@@ -0,0 +1,179 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// Timestamped is a thread-safe Bitmaptable that keeps track of when it was
+// last modified.
+type Timestamped interface {
+	Bitmaptable
+
+	// LastModified returns the time of the last successful mutation, or the
+	// creation time of the table if it hasn't been modified yet. The time is
+	// recorded under the lock of the table together with the mutation, so a
+	// reader that observes a mutation also observes its time.
+	LastModified() time.Time
+}
+
+// NewTimestamped creates a new thread-safe Timestamped instance.
+// The clock is used to timestamp mutations, it defaults to time.Now if nil.
+// It is called while the table is locked and must not call methods of it.
+func NewTimestamped(rows, columns int, clock func() time.Time) Timestamped {
+	if clock == nil {
+		clock = time.Now
+	}
+	t := newTS(rows, columns)
+	return &timestamped{
+		Bitmaptable: t,
+		ts:          t,
+		clock:       clock,
+		modified:    clock(),
+	}
+}
+
+// timestamped wraps a thread-safe table and timestamps every mutation.
+// Methods that don't mutate the table are promoted from the embedded table.
+type timestamped struct {
+	Bitmaptable
+	ts       *ts // The embedded table, of which the lock guards modified.
+	clock    func() time.Time
+	modified time.Time
+}
+
+// threadSafe marks the table as safe for concurrent use.
+func (t *timestamped) threadSafe() {}
+
+// view calls fn with the data of the embedded table.
+func (t *timestamped) view(fn func(rows, columns, stride int, data []byte)) {
+	viewData(t.Bitmaptable, fn)
+}
+
+// LastModified implements Timestamped.LastModified
+func (t *timestamped) LastModified() time.Time {
+	t.ts.mu.Lock()
+	modified := t.modified
+	t.ts.mu.Unlock()
+	return modified
+}
+
+// mutate calls fn with the table while holding its lock, and records the
+// current time as the modification time before releasing it if fn reports
+// that it changed the table.
+func (t *timestamped) mutate(fn func(b *bitmaptable) (changed bool)) {
+	t.ts.mu.Lock()
+	if fn(t.ts.b) {
+		t.modified = t.clock()
+	}
+	t.ts.mu.Unlock()
+}
+
+// update is mutate for operations that change the table unless they fail.
+func (t *timestamped) update(fn func(b *bitmaptable) error) error {
+	var err error
+	t.mutate(func(b *bitmaptable) bool {
+		err = fn(b)
+		return err == nil
+	})
+	return err
+}
+
+// Set implements Bitmaptable.Set
+func (t *timestamped) Set(row int, column int, value bool) error {
+	return t.update(func(b *bitmaptable) error { return b.Set(row, column, value) })
+}
+
+// Free implements Bitmaptable.Free
+func (t *timestamped) Free() {
+	t.mutate(func(b *bitmaptable) bool {
+		if b.closed {
+			return false
+		}
+		b.Free()
+		return true
+	})
+}
+
+// FlipColumn implements Bitmaptable.FlipColumn
+func (t *timestamped) FlipColumn(column int) error {
+	return t.update(func(b *bitmaptable) error { return b.FlipColumn(column) })
+}
+
+// FlipRow implements Bitmaptable.FlipRow
+func (t *timestamped) FlipRow(row int) error {
+	return t.update(func(b *bitmaptable) error { return b.FlipRow(row) })
+}
+
+// ToggleMask implements Bitmaptable.ToggleMask
+func (t *timestamped) ToggleMask(mask Bitmaptable) error {
+	rows, columns, stride, data := mask.Rows(), mask.Columns(), mask.Stride(), mask.SafeData()
+	return t.update(func(b *bitmaptable) error { return b.toggleMask(rows, columns, stride, data) })
+}
+
+// SortRows implements Bitmaptable.SortRows
+func (t *timestamped) SortRows(less func(a, b []bool) bool) {
+	t.mutate(func(b *bitmaptable) bool {
+		data := b.SafeData()
+		b.SortRows(less)
+		return !bytes.Equal(data, b.bitmap)
+	})
+}
+
+// AddColumns implements Bitmaptable.AddColumns
+func (t *timestamped) AddColumns(n int) error {
+	return t.update(func(b *bitmaptable) error { return b.AddColumns(n) })
+}
+
+// BroadcastRowOr implements Bitmaptable.BroadcastRowOr
+func (t *timestamped) BroadcastRowOr(pattern []bool) error {
+	return t.update(func(b *bitmaptable) error { return b.BroadcastRowOr(pattern) })
+}
+
+// ClearColumn implements Bitmaptable.ClearColumn
+func (t *timestamped) ClearColumn(column int) error {
+	return t.update(func(b *bitmaptable) error { return b.ClearColumn(column) })
+}
+
+// ApplyChanges implements Bitmaptable.ApplyChanges
+func (t *timestamped) ApplyChanges(changes map[Coord]bool) error {
+	return t.update(func(b *bitmaptable) error { return b.ApplyChanges(changes) })
+}
+
+// Set2Bit implements Bitmaptable.Set2Bit
+func (t *timestamped) Set2Bit(row, startColumn int, v uint8) error {
+	return t.update(func(b *bitmaptable) error { return b.Set2Bit(row, startColumn, v) })
+}
+
+// ClearRowsBelow implements Bitmaptable.ClearRowsBelow
+func (t *timestamped) ClearRowsBelow(row int) error {
+	return t.update(func(b *bitmaptable) error { return b.ClearRowsBelow(row) })
+}
+
+// AppendTable implements Bitmaptable.AppendTable
+func (t *timestamped) AppendTable(other Bitmaptable) error {
+	rows, columns, stride, data := other.Rows(), other.Columns(), other.Stride(), other.SafeData()
+	return t.update(func(b *bitmaptable) error { return b.appendData(rows, columns, stride, data) })
+}
+
+// PermuteRows implements Bitmaptable.PermuteRows
+func (t *timestamped) PermuteRows(perm []int) error {
+	return t.update(func(b *bitmaptable) error { return b.PermuteRows(perm) })
+}
+
+// TrimTrailing implements Bitmaptable.TrimTrailing
+func (t *timestamped) TrimTrailing() int {
+	var rows int
+	t.mutate(func(b *bitmaptable) bool {
+		before := b.rows
+		rows = b.TrimTrailing()
+		return rows != before
+	})
+	return rows
+}
+
+// ConsumeUpdates implements Bitmaptable.ConsumeUpdates
+func (t *timestamped) ConsumeUpdates(ctx context.Context, ch <-chan Update) error {
+	return consumeUpdates(ctx, ch, t.Set)
+}
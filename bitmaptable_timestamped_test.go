@@ -0,0 +1,124 @@
+package bitmaptable
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimestamped(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	b := NewTimestamped(10, 5, clock)
+	created := b.LastModified()
+
+	b.Get(1, 1)
+	b.EmptyColumns()
+	b.Data(true)
+	if !b.LastModified().Equal(created) {
+		t.Fatal("reads mustn't update the timestamp")
+	}
+
+	b.Set(1, 1, true)
+	first := b.LastModified()
+	if !first.After(created) {
+		t.Fatal("set must update the timestamp")
+	}
+	if b.LastModified() != first {
+		t.Fatal("timestamp must be stable across reads")
+	}
+
+	if err := b.Set(10, 1, true); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if b.LastModified() != first {
+		t.Fatal("failed mutations mustn't update the timestamp")
+	}
+
+	b.FlipRow(2)
+	if !b.LastModified().After(first) {
+		t.Fatal("bulk mutations must update the timestamp")
+	}
+	if v, _ := b.Get(2, 4); !v {
+		t.Fatal("mutation wasn't applied")
+	}
+}
+
+func TestTimestampedConsumeUpdates(t *testing.T) {
+	now := time.Unix(100, 0)
+	b := NewTimestamped(4, 4, func() time.Time { return now })
+	now = time.Unix(200, 0)
+	ch := make(chan Update, 1)
+	ch <- Update{1, 1, true}
+	close(ch)
+	if err := b.ConsumeUpdates(context.Background(), ch); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !b.LastModified().Equal(now) {
+		t.Fatal("consumed updates must be timestamped")
+	}
+}
+
+func TestTimestampedNoop(t *testing.T) {
+	now := time.Unix(100, 0)
+	b := NewTimestamped(4, 4, func() time.Time { return now })
+	b.Set(1, 1, true)
+	b.Set(3, 2, true)
+
+	now = time.Unix(200, 0)
+	b.SortRows(func(x, y []bool) bool { return false })
+	b.TrimTrailing()
+	if !b.LastModified().Equal(time.Unix(100, 0)) {
+		t.Fatal("mutations without effect mustn't update the timestamp")
+	}
+	b.SortRows(func(x, y []bool) bool { return x[2] && !y[2] })
+	if !b.LastModified().Equal(now) {
+		t.Fatal("sorting must update the timestamp")
+	}
+
+	// Sorting moved row 3 to the front, so only row 0 remains set.
+	b.Set(2, 1, false)
+	now = time.Unix(400, 0)
+	if b.TrimTrailing() != 1 || !b.LastModified().Equal(now) {
+		t.Fatal("trimming must update the timestamp")
+	}
+
+	now = time.Unix(500, 0)
+	b.Free()
+	now = time.Unix(600, 0)
+	b.Free()
+	b.SortRows(func(x, y []bool) bool { return true })
+	b.TrimTrailing()
+	if !b.LastModified().Equal(time.Unix(500, 0)) {
+		t.Fatal("operations on a freed table mustn't update the timestamp")
+	}
+}
+
+func TestTimestampedUnderLock(t *testing.T) {
+	var mu *sync.Mutex
+	unlocked := 0
+	clock := func() time.Time {
+		if mu != nil && mu.TryLock() {
+			mu.Unlock()
+			unlocked++
+		}
+		return time.Now()
+	}
+	b := NewTimestamped(4, 4, clock)
+	mu = b.(*timestamped).ts.mu.(*sync.Mutex)
+
+	b.Set(1, 1, true)
+	b.FlipRow(2)
+	b.AppendTable(b)
+	b.SortRows(func(x, y []bool) bool { return x[0] && !y[0] })
+	b.TrimTrailing()
+	b.Free()
+	if unlocked != 0 {
+		t.Fatal("mutations must be timestamped while the table is locked", unlocked)
+	}
+}
@@ -1,11 +1,82 @@
 package bitmaptable
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/boljen/go-bitmap"
+)
+
+// Swappable is a thread-safe Bitmaptable of which the data can be replaced as
+// a whole. Tables created with NewTS implement it.
+type Swappable interface {
+	Bitmaptable
+
+	// SwapData replaces the data of the table with newData, which must hold
+	// exactly the bytes of Data for the current dimensions, and returns the
+	// previous data. Padding bits of newData are cleared. Concurrent users
+	// see either the old or the new data, never a mix of both.
+	SwapData(newData []byte) (old []byte, err error)
+}
+
+// RowLocker is a thread-safe Bitmaptable of which single rows can be locked,
+// so that compound operations on a row don't have to exclude the whole table.
+// Tables created with NewTS and NewTSWithLocking implement it.
+type RowLocker interface {
+	Bitmaptable
+
+	// LockRow locks the provided row and returns the function that unlocks
+	// it, which must be called exactly once. Row locks only exclude other
+	// LockRow calls for the same row; the methods of the table don't take
+	// them. To avoid deadlocks, always unlock the row and don't lock
+	// another row while holding one.
+	LockRow(row int) (unlock func(), err error)
+}
 
 // ts is a Thread-Safe implementation of the Bitmaptable struct.
 type ts struct {
-	mu *sync.Mutex
-	b  *bitmaptable
+	mu   sync.Locker
+	b    *bitmaptable
+	rows rowLocks
+}
+
+// rowLocks holds a lock for every row that is locked or waited for.
+type rowLocks struct {
+	mu    sync.Mutex
+	locks map[int]*rowLock
+}
+
+type rowLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the provided row and returns the function that unlocks it.
+func (r *rowLocks) lock(row int) func() {
+	r.mu.Lock()
+	if r.locks == nil {
+		r.locks = make(map[int]*rowLock)
+	}
+	l := r.locks[row]
+	if l == nil {
+		l = new(rowLock)
+		r.locks[row] = l
+	}
+	l.refs++
+	r.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		r.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(r.locks, row)
+		}
+		r.mu.Unlock()
+	}
 }
 
 func newTS(rows, columns int) *ts {
@@ -15,14 +86,30 @@ func newTS(rows, columns int) *ts {
 	}
 }
 
+// threadSafe marks the table as safe for concurrent use.
+func (t *ts) threadSafe() {}
+
+// view calls fn with the data of the table while holding the lock.
+func (t *ts) view(fn func(rows, columns, stride int, data []byte)) {
+	t.mu.Lock()
+	t.b.view(fn)
+	t.mu.Unlock()
+}
+
 // Rows implements Bitmaptable.Rows
 func (t *ts) Rows() int {
-	return t.b.Rows()
+	t.mu.Lock()
+	rows := t.b.Rows()
+	t.mu.Unlock()
+	return rows
 }
 
 // Columns implements Bitmaptable.Columns
 func (t *ts) Columns() int {
-	return t.b.Columns()
+	t.mu.Lock()
+	columns := t.b.Columns()
+	t.mu.Unlock()
+	return columns
 }
 
 // Data implements Bitmaptable.Data
@@ -33,9 +120,28 @@ func (t *ts) Data(c bool) []byte {
 	return data
 }
 
+// SafeData implements Bitmaptable.SafeData
+func (t *ts) SafeData() []byte {
+	t.mu.Lock()
+	data := t.b.SafeData()
+	t.mu.Unlock()
+	return data
+}
+
+// WordAlignment implements Bitmaptable.WordAlignment
+func (t *ts) WordAlignment() int {
+	t.mu.Lock()
+	n := t.b.WordAlignment()
+	t.mu.Unlock()
+	return n
+}
+
 // Get implements Bitmaptable.Get
 func (t *ts) Get(row int, column int) (bool, error) {
-	return t.b.Get(row, column)
+	t.mu.Lock()
+	v, err := t.b.Get(row, column)
+	t.mu.Unlock()
+	return v, err
 }
 
 // Set implements Bitmaptable.Set
@@ -45,3 +151,643 @@ func (t *ts) Set(row int, column int, value bool) error {
 	t.mu.Unlock()
 	return err
 }
+
+// PaddingBits implements Bitmaptable.PaddingBits
+func (t *ts) PaddingBits() int {
+	t.mu.Lock()
+	bits := t.b.PaddingBits()
+	t.mu.Unlock()
+	return bits
+}
+
+// PaddingSet implements Bitmaptable.PaddingSet
+func (t *ts) PaddingSet() bool {
+	t.mu.Lock()
+	set := t.b.PaddingSet()
+	t.mu.Unlock()
+	return set
+}
+
+// Locate implements Bitmaptable.Locate
+func (t *ts) Locate(row, column int) (int, int, error) {
+	t.mu.Lock()
+	byteIndex, bitIndex, err := t.b.Locate(row, column)
+	t.mu.Unlock()
+	return byteIndex, bitIndex, err
+}
+
+// ValidIndex implements Bitmaptable.ValidIndex
+func (t *ts) ValidIndex(row, column int) bool {
+	t.mu.Lock()
+	valid := t.b.ValidIndex(row, column)
+	t.mu.Unlock()
+	return valid
+}
+
+// Free implements Bitmaptable.Free
+func (t *ts) Free() {
+	t.mu.Lock()
+	t.b.Free()
+	t.mu.Unlock()
+}
+
+// EmptyColumns implements Bitmaptable.EmptyColumns
+func (t *ts) EmptyColumns() []int {
+	t.mu.Lock()
+	columns := t.b.EmptyColumns()
+	t.mu.Unlock()
+	return columns
+}
+
+// RangeRowsParallel implements Bitmaptable.RangeRowsParallel
+func (t *ts) RangeRowsParallel(workers int, fn func(row int, values []bool)) {
+	t.mu.Lock()
+	t.b.RangeRowsParallel(workers, fn)
+	t.mu.Unlock()
+}
+
+// SampleSetBits implements Bitmaptable.SampleSetBits
+func (t *ts) SampleSetBits(n int, rng *rand.Rand) []Coord {
+	t.mu.Lock()
+	sample := t.b.SampleSetBits(n, rng)
+	t.mu.Unlock()
+	return sample
+}
+
+// Marshal implements Bitmaptable.Marshal
+func (t *ts) Marshal() ([]byte, error) {
+	t.mu.Lock()
+	data, err := t.b.Marshal()
+	t.mu.Unlock()
+	return data, err
+}
+
+// RowMajority implements Bitmaptable.RowMajority
+func (t *ts) RowMajority(row int) (bool, error) {
+	t.mu.Lock()
+	v, err := t.b.RowMajority(row)
+	t.mu.Unlock()
+	return v, err
+}
+
+// FlipColumn implements Bitmaptable.FlipColumn
+func (t *ts) FlipColumn(column int) error {
+	t.mu.Lock()
+	err := t.b.FlipColumn(column)
+	t.mu.Unlock()
+	return err
+}
+
+// FlipRow implements Bitmaptable.FlipRow
+func (t *ts) FlipRow(row int) error {
+	t.mu.Lock()
+	err := t.b.FlipRow(row)
+	t.mu.Unlock()
+	return err
+}
+
+// RowsWithinHamming implements Bitmaptable.RowsWithinHamming
+func (t *ts) RowsWithinHamming(pattern []bool, maxDist int) ([]int, error) {
+	t.mu.Lock()
+	rows, err := t.b.RowsWithinHamming(pattern, maxDist)
+	t.mu.Unlock()
+	return rows, err
+}
+
+// WriteCSV implements Bitmaptable.WriteCSV
+func (t *ts) WriteCSV(w io.Writer) error {
+	t.mu.Lock()
+	err := t.b.WriteCSV(w)
+	t.mu.Unlock()
+	return err
+}
+
+// ToggleMask implements Bitmaptable.ToggleMask
+func (t *ts) ToggleMask(mask Bitmaptable) error {
+	rows, columns, stride, data := mask.Rows(), mask.Columns(), mask.Stride(), mask.SafeData()
+	t.mu.Lock()
+	err := t.b.toggleMask(rows, columns, stride, data)
+	t.mu.Unlock()
+	return err
+}
+
+// EstimateCompressedSize implements Bitmaptable.EstimateCompressedSize
+func (t *ts) EstimateCompressedSize() int {
+	t.mu.Lock()
+	size := t.b.EstimateCompressedSize()
+	t.mu.Unlock()
+	return size
+}
+
+// ShiftColumn implements Bitmaptable.ShiftColumn
+func (t *ts) ShiftColumn(column, k int) (Bitmaptable, error) {
+	t.mu.Lock()
+	r, err := t.b.ShiftColumn(column, k)
+	t.mu.Unlock()
+	return r, err
+}
+
+// ColumnAsBitmap implements Bitmaptable.ColumnAsBitmap
+func (t *ts) ColumnAsBitmap(column int) (bitmap.Bitmap, error) {
+	t.mu.Lock()
+	bm, err := t.b.ColumnAsBitmap(column)
+	t.mu.Unlock()
+	return bm, err
+}
+
+// SortRows implements Bitmaptable.SortRows
+func (t *ts) SortRows(less func(a, b []bool) bool) {
+	t.mu.Lock()
+	t.b.SortRows(less)
+	t.mu.Unlock()
+}
+
+// Stride implements Bitmaptable.Stride
+func (t *ts) Stride() int {
+	t.mu.Lock()
+	stride := t.b.Stride()
+	t.mu.Unlock()
+	return stride
+}
+
+// AddColumns implements Bitmaptable.AddColumns
+func (t *ts) AddColumns(n int) error {
+	t.mu.Lock()
+	err := t.b.AddColumns(n)
+	t.mu.Unlock()
+	return err
+}
+
+// OverheadBytes implements Bitmaptable.OverheadBytes
+func (t *ts) OverheadBytes() int {
+	t.mu.Lock()
+	overhead := t.b.OverheadBytes()
+	t.mu.Unlock()
+	return overhead
+}
+
+// EqualTo implements Bitmaptable.EqualTo
+func (t *ts) EqualTo(other Bitmaptable) bool {
+	return Equal(t, other)
+}
+
+// GoLiteral implements Bitmaptable.GoLiteral
+func (t *ts) GoLiteral(varName string) string {
+	t.mu.Lock()
+	literal := t.b.GoLiteral(varName)
+	t.mu.Unlock()
+	return literal
+}
+
+// Count implements Bitmaptable.Count
+func (t *ts) Count() int {
+	t.mu.Lock()
+	count := t.b.Count()
+	t.mu.Unlock()
+	return count
+}
+
+// Density implements Bitmaptable.Density
+func (t *ts) Density() float64 {
+	t.mu.Lock()
+	density := t.b.Density()
+	t.mu.Unlock()
+	return density
+}
+
+// BroadcastRowOr implements Bitmaptable.BroadcastRowOr
+func (t *ts) BroadcastRowOr(pattern []bool) error {
+	t.mu.Lock()
+	err := t.b.BroadcastRowOr(pattern)
+	t.mu.Unlock()
+	return err
+}
+
+// FirstDifference implements Bitmaptable.FirstDifference
+func (t *ts) FirstDifference(other Bitmaptable) (int, int, bool, error) {
+	return firstDifference(t, other)
+}
+
+// ClearColumn implements Bitmaptable.ClearColumn
+func (t *ts) ClearColumn(column int) error {
+	t.mu.Lock()
+	err := t.b.ClearColumn(column)
+	t.mu.Unlock()
+	return err
+}
+
+// SelectColumns implements Bitmaptable.SelectColumns
+func (t *ts) SelectColumns(columns []int) (Bitmaptable, error) {
+	t.mu.Lock()
+	r, err := t.b.SelectColumns(columns)
+	t.mu.Unlock()
+	return r, err
+}
+
+// CountRegion implements Bitmaptable.CountRegion
+func (t *ts) CountRegion(startRow, startCol, nRows, nCols int) (int, error) {
+	t.mu.Lock()
+	count, err := t.b.CountRegion(startRow, startCol, nRows, nCols)
+	t.mu.Unlock()
+	return count, err
+}
+
+// WriteBase64 implements Bitmaptable.WriteBase64
+func (t *ts) WriteBase64(w io.Writer) error {
+	t.mu.Lock()
+	err := t.b.WriteBase64(w)
+	t.mu.Unlock()
+	return err
+}
+
+// RangeSetBitsParallel implements Bitmaptable.RangeSetBitsParallel
+func (t *ts) RangeSetBitsParallel(workers int, fn func(row, column int)) {
+	t.mu.Lock()
+	t.b.RangeSetBitsParallel(workers, fn)
+	t.mu.Unlock()
+}
+
+// ColumnParity implements Bitmaptable.ColumnParity
+func (t *ts) ColumnParity(column int) (bool, error) {
+	t.mu.Lock()
+	parity, err := t.b.ColumnParity(column)
+	t.mu.Unlock()
+	return parity, err
+}
+
+// TopRowsByPopcount implements Bitmaptable.TopRowsByPopcount
+func (t *ts) TopRowsByPopcount(n int) []RowCount {
+	t.mu.Lock()
+	top := t.b.TopRowsByPopcount(n)
+	t.mu.Unlock()
+	return top
+}
+
+// ApplyChanges implements Bitmaptable.ApplyChanges
+func (t *ts) ApplyChanges(changes map[Coord]bool) error {
+	t.mu.Lock()
+	err := t.b.ApplyChanges(changes)
+	t.mu.Unlock()
+	return err
+}
+
+// Cursor implements Bitmaptable.Cursor
+func (t *ts) Cursor() *SetBitCursor {
+	return &SetBitCursor{b: t.b, mu: t.mu, i: -1}
+}
+
+// OrRowsInto implements Bitmaptable.OrRowsInto
+func (t *ts) OrRowsInto(dst Bitmaptable, rowMap []int) error {
+	rows, columns := dst.Rows(), dst.Columns()
+	t.mu.Lock()
+	coords, err := t.b.mapSetBits(rows, columns, rowMap)
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return setCoords(dst, coords)
+}
+
+// AnyInColumn implements Bitmaptable.AnyInColumn
+func (t *ts) AnyInColumn(column int) (bool, error) {
+	t.mu.Lock()
+	v, err := t.b.AnyInColumn(column)
+	t.mu.Unlock()
+	return v, err
+}
+
+// AllInColumn implements Bitmaptable.AllInColumn
+func (t *ts) AllInColumn(column int) (bool, error) {
+	t.mu.Lock()
+	v, err := t.b.AllInColumn(column)
+	t.mu.Unlock()
+	return v, err
+}
+
+// DownsampleRowsAnd implements Bitmaptable.DownsampleRowsAnd
+func (t *ts) DownsampleRowsAnd(k int) (Bitmaptable, error) {
+	t.mu.Lock()
+	r, err := t.b.DownsampleRowsAnd(k)
+	t.mu.Unlock()
+	return r, err
+}
+
+// Get2Bit implements Bitmaptable.Get2Bit
+func (t *ts) Get2Bit(row, startColumn int) (uint8, error) {
+	t.mu.Lock()
+	v, err := t.b.Get2Bit(row, startColumn)
+	t.mu.Unlock()
+	return v, err
+}
+
+// Set2Bit implements Bitmaptable.Set2Bit
+func (t *ts) Set2Bit(row, startColumn int, v uint8) error {
+	t.mu.Lock()
+	err := t.b.Set2Bit(row, startColumn, v)
+	t.mu.Unlock()
+	return err
+}
+
+// ColumnCounts implements Bitmaptable.ColumnCounts
+func (t *ts) ColumnCounts() []int {
+	t.mu.Lock()
+	c := t.b.ColumnCounts()
+	t.mu.Unlock()
+	return c
+}
+
+// ColumnEntropies implements Bitmaptable.ColumnEntropies
+func (t *ts) ColumnEntropies() []float64 {
+	t.mu.Lock()
+	e := t.b.ColumnEntropies()
+	t.mu.Unlock()
+	return e
+}
+
+// WriteFramed implements Bitmaptable.WriteFramed
+func (t *ts) WriteFramed(w io.Writer) (int, error) {
+	t.mu.Lock()
+	n, err := t.b.WriteFramed(w)
+	t.mu.Unlock()
+	return n, err
+}
+
+// MostCommonRow implements Bitmaptable.MostCommonRow
+func (t *ts) MostCommonRow() ([]bool, int) {
+	t.mu.Lock()
+	pattern, count := t.b.MostCommonRow()
+	t.mu.Unlock()
+	return pattern, count
+}
+
+// RangeAll implements Bitmaptable.RangeAll
+func (t *ts) RangeAll(fn func(row, column int, value bool) bool) {
+	t.mu.Lock()
+	t.b.RangeAll(fn)
+	t.mu.Unlock()
+}
+
+// ColumnCoOccurrence implements Bitmaptable.ColumnCoOccurrence
+func (t *ts) ColumnCoOccurrence() [][]int {
+	t.mu.Lock()
+	m := t.b.ColumnCoOccurrence()
+	t.mu.Unlock()
+	return m
+}
+
+// ClearRowsBelow implements Bitmaptable.ClearRowsBelow
+func (t *ts) ClearRowsBelow(row int) error {
+	t.mu.Lock()
+	err := t.b.ClearRowsBelow(row)
+	t.mu.Unlock()
+	return err
+}
+
+// AppendTable implements Bitmaptable.AppendTable
+func (t *ts) AppendTable(other Bitmaptable) error {
+	rows, columns, stride, data := other.Rows(), other.Columns(), other.Stride(), other.SafeData()
+	t.mu.Lock()
+	err := t.b.appendData(rows, columns, stride, data)
+	t.mu.Unlock()
+	return err
+}
+
+// ColumnsEqual implements Bitmaptable.ColumnsEqual
+func (t *ts) ColumnsEqual(a, b int) (bool, error) {
+	t.mu.Lock()
+	v, err := t.b.ColumnsEqual(a, b)
+	t.mu.Unlock()
+	return v, err
+}
+
+// BitReversedPermutation implements Bitmaptable.BitReversedPermutation
+func (t *ts) BitReversedPermutation() []int {
+	t.mu.Lock()
+	perm := t.b.BitReversedPermutation()
+	t.mu.Unlock()
+	return perm
+}
+
+// PermuteRows implements Bitmaptable.PermuteRows
+func (t *ts) PermuteRows(perm []int) error {
+	t.mu.Lock()
+	err := t.b.PermuteRows(perm)
+	t.mu.Unlock()
+	return err
+}
+
+// NonEmptyRowCount implements Bitmaptable.NonEmptyRowCount
+func (t *ts) NonEmptyRowCount() int {
+	t.mu.Lock()
+	n := t.b.NonEmptyRowCount()
+	t.mu.Unlock()
+	return n
+}
+
+// WritePNG implements Bitmaptable.WritePNG
+func (t *ts) WritePNG(w io.Writer) error {
+	t.mu.Lock()
+	err := t.b.WritePNG(w)
+	t.mu.Unlock()
+	return err
+}
+
+// RollingOrColumn implements Bitmaptable.RollingOrColumn
+func (t *ts) RollingOrColumn(column, window int) ([]bool, error) {
+	t.mu.Lock()
+	r, err := t.b.RollingOrColumn(column, window)
+	t.mu.Unlock()
+	return r, err
+}
+
+// MarshalColumnar implements Bitmaptable.MarshalColumnar
+func (t *ts) MarshalColumnar() ([]byte, error) {
+	t.mu.Lock()
+	data, err := t.b.MarshalColumnar()
+	t.mu.Unlock()
+	return data, err
+}
+
+// RowsMatchingAny implements Bitmaptable.RowsMatchingAny
+func (t *ts) RowsMatchingAny(patterns [][]bool) ([]int, error) {
+	t.mu.Lock()
+	rows, err := t.b.RowsMatchingAny(patterns)
+	t.mu.Unlock()
+	return rows, err
+}
+
+// NonZeroByteIndex implements Bitmaptable.NonZeroByteIndex
+func (t *ts) NonZeroByteIndex() []bool {
+	t.mu.Lock()
+	index := t.b.NonZeroByteIndex()
+	t.mu.Unlock()
+	return index
+}
+
+// SwapData implements Swappable.SwapData
+func (t *ts) SwapData(newData []byte) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.b.writable(); err != nil {
+		return nil, err
+	}
+	if need := len(t.b.bitmap); len(newData) != need {
+		return nil, fmt.Errorf("%w: need %d bytes, got %d", ErrIllegalData, need, len(newData))
+	}
+	old := t.b.bitmap
+	t.b.bitmap = newData[:len(newData):len(newData)]
+	t.b.normalize()
+	return old, nil
+}
+
+// IsColumnMonotone implements Bitmaptable.IsColumnMonotone
+func (t *ts) IsColumnMonotone(column int) (bool, error) {
+	t.mu.Lock()
+	v, err := t.b.IsColumnMonotone(column)
+	t.mu.Unlock()
+	return v, err
+}
+
+// FirstTrueInMonotoneColumn implements Bitmaptable.FirstTrueInMonotoneColumn
+func (t *ts) FirstTrueInMonotoneColumn(column int) (int, error) {
+	t.mu.Lock()
+	row, err := t.b.FirstTrueInMonotoneColumn(column)
+	t.mu.Unlock()
+	return row, err
+}
+
+// MarshalWithMeta implements Bitmaptable.MarshalWithMeta
+func (t *ts) MarshalWithMeta(meta []byte) ([]byte, error) {
+	t.mu.Lock()
+	data, err := t.b.MarshalWithMeta(meta)
+	t.mu.Unlock()
+	return data, err
+}
+
+// TrimTrailing implements Bitmaptable.TrimTrailing
+func (t *ts) TrimTrailing() int {
+	t.mu.Lock()
+	rows := t.b.TrimTrailing()
+	t.mu.Unlock()
+	return rows
+}
+
+// LongestRunInColumn implements Bitmaptable.LongestRunInColumn
+func (t *ts) LongestRunInColumn(column int, value bool) (int, int, error) {
+	t.mu.Lock()
+	start, length, err := t.b.LongestRunInColumn(column, value)
+	t.mu.Unlock()
+	return start, length, err
+}
+
+// SplitColumns implements Bitmaptable.SplitColumns
+func (t *ts) SplitColumns() []Bitmaptable {
+	t.mu.Lock()
+	tables := t.b.SplitColumns()
+	t.mu.Unlock()
+	return tables
+}
+
+// VerticalAndCount implements Bitmaptable.VerticalAndCount
+func (t *ts) VerticalAndCount(column int) (int, error) {
+	t.mu.Lock()
+	n, err := t.b.VerticalAndCount(column)
+	t.mu.Unlock()
+	return n, err
+}
+
+// RotateColumn implements Bitmaptable.RotateColumn
+func (t *ts) RotateColumn(column, k int) (Bitmaptable, error) {
+	t.mu.Lock()
+	r, err := t.b.RotateColumn(column, k)
+	t.mu.Unlock()
+	return r, err
+}
+
+// RowParityColumn implements Bitmaptable.RowParityColumn
+func (t *ts) RowParityColumn() Bitmaptable {
+	t.mu.Lock()
+	r := t.b.RowParityColumn()
+	t.mu.Unlock()
+	return r
+}
+
+// SetBitsInRowRange implements Bitmaptable.SetBitsInRowRange
+func (t *ts) SetBitsInRowRange(column, startRow, endRow int) ([]int, error) {
+	t.mu.Lock()
+	rows, err := t.b.SetBitsInRowRange(column, startRow, endRow)
+	t.mu.Unlock()
+	return rows, err
+}
+
+// ConsumeUpdates implements Bitmaptable.ConsumeUpdates
+func (t *ts) ConsumeUpdates(ctx context.Context, ch <-chan Update) error {
+	return consumeUpdates(ctx, ch, t.Set)
+}
+
+// ColumnRuns implements Bitmaptable.ColumnRuns
+func (t *ts) ColumnRuns(column int) ([]Run, error) {
+	t.mu.Lock()
+	runs, err := t.b.ColumnRuns(column)
+	t.mu.Unlock()
+	return runs, err
+}
+
+// ApproxCount implements Bitmaptable.ApproxCount
+func (t *ts) ApproxCount(sampleBytes int, rng *rand.Rand) int {
+	t.mu.Lock()
+	n := t.b.ApproxCount(sampleBytes, rng)
+	t.mu.Unlock()
+	return n
+}
+
+// LockRow implements RowLocker.LockRow
+func (t *ts) LockRow(row int) (func(), error) {
+	t.mu.Lock()
+	err := t.b.checkRow(row)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return t.rows.lock(row), nil
+}
+
+// ChangesSince implements Bitmaptable.ChangesSince
+func (t *ts) ChangesSince(snap []byte) ([]Coord, error) {
+	t.mu.Lock()
+	changes, err := t.b.ChangesSince(snap)
+	t.mu.Unlock()
+	return changes, err
+}
+
+// WriteBits implements Bitmaptable.WriteBits
+func (t *ts) WriteBits(bw BitWriter) error {
+	t.mu.Lock()
+	err := t.b.WriteBits(bw)
+	t.mu.Unlock()
+	return err
+}
+
+// CountZeros implements Bitmaptable.CountZeros
+func (t *ts) CountZeros() int {
+	t.mu.Lock()
+	n := t.b.CountZeros()
+	t.mu.Unlock()
+	return n
+}
+
+// FindRow implements Bitmaptable.FindRow
+func (t *ts) FindRow(pred func(values []bool) bool) (int, error) {
+	t.mu.Lock()
+	row, err := t.b.FindRow(pred)
+	t.mu.Unlock()
+	return row, err
+}
+
+// Pack16 implements Bitmaptable.Pack16
+func (t *ts) Pack16() ([16]byte, bool) {
+	t.mu.Lock()
+	data, ok := t.b.Pack16()
+	t.mu.Unlock()
+	return data, ok
+}
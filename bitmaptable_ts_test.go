@@ -1,6 +1,10 @@
 package bitmaptable
 
-import "testing"
+import (
+	"errors"
+	"sync"
+	"testing"
+)
 
 func TestTS(t *testing.T) {
 	bm := newTS(10, 5)
@@ -54,3 +58,172 @@ func TestTSGetSet(t *testing.T) {
 		t.Fatal("illegal index")
 	}
 }
+
+func TestTSSafeData(t *testing.T) {
+	b := NewTS(100, 8)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 800; i++ {
+			b.Set(i/8, i%8, true)
+		}
+	}()
+
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		// Bits are set in order, so a consistent snapshot is a run of set
+		// bits followed by clear bits only.
+		data := b.SafeData()
+		clear := false
+		for i := 0; i < 800; i++ {
+			v := data[i/8]&(1<<uint(i%8)) != 0
+			if v && clear {
+				t.Fatal("inconsistent snapshot")
+			}
+			clear = clear || !v
+		}
+	}
+
+	data := b.SafeData()
+	data[0] = 0
+	if v, _ := b.Get(0, 0); !v {
+		t.Fatal("safe data must be a copy")
+	}
+}
+
+func TestTSSwapData(t *testing.T) {
+	b := NewTS(20, 6).(Swappable)
+	b.Set(3, 2, true)
+	full := make([]byte, 15)
+	for i := range full {
+		full[i] = 0xff
+	}
+
+	old, err := b.SwapData(full)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(old) != 15 || old[2] != 0x10 {
+		t.Fatal("old data must be returned")
+	}
+	if b.Count() != 120 {
+		t.Fatal("new data must be used")
+	}
+	if _, err := b.SwapData(make([]byte, 14)); !errors.Is(err, ErrIllegalData) {
+		t.Fatal("illegal data must be returned", err)
+	}
+	if _, err := b.SwapData(make([]byte, 16)); !errors.Is(err, ErrIllegalData) {
+		t.Fatal("illegal data must be returned", err)
+	}
+
+	padded := NewTS(3, 5).(Swappable)
+	if _, err := padded.SwapData([]byte{0xff, 0xff, 0xff}[:2]); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if padded.PaddingSet() || padded.Count() != 15 || padded.WordAlignment() != 2 {
+		t.Fatal("padding must be cleared and the real alignment reported")
+	}
+
+	// Readers must only ever see one of both tables as a whole.
+	other := make([]byte, 15)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			other, _ = b.SwapData(other)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if c := b.Count(); c != 0 && c != 120 {
+			t.Fatal("inconsistent state", c)
+		}
+		b.WordAlignment()
+	}
+}
+
+func TestTSLockRow(t *testing.T) {
+	for _, b := range []RowLocker{NewTS(4, 8).(RowLocker), NewTSWithLocking(4, 8, PerRowStriped(2)).(RowLocker)} {
+		// Every goroutine increments the counter stored in the columns of
+		// its row, which only stays consistent if the row is locked.
+		var wg sync.WaitGroup
+		for i := 0; i < 200; i++ {
+			wg.Add(1)
+			go func(row int) {
+				defer wg.Done()
+				unlock, err := b.LockRow(row)
+				if err != nil {
+					t.Error("unexpected error", err)
+					return
+				}
+				defer unlock()
+				n := 0
+				for column := 0; column < 8; column++ {
+					if v, _ := b.Get(row, column); v {
+						n |= 1 << uint(column)
+					}
+				}
+				n++
+				for column := 0; column < 8; column++ {
+					b.Set(row, column, n&(1<<uint(column)) != 0)
+				}
+			}(i % 4)
+		}
+		wg.Wait()
+		for row := 0; row < 4; row++ {
+			n := 0
+			for column := 0; column < 8; column++ {
+				if v, _ := b.Get(row, column); v {
+					n |= 1 << uint(column)
+				}
+			}
+			if n != 50 {
+				t.Fatal("wrong counter", row, n)
+			}
+		}
+		if _, err := b.LockRow(4); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestTSCombineConcurrent(t *testing.T) {
+	// Other tables are read while they are written to concurrently, which
+	// the race detector reports unless they are read under their lock.
+	b, other := NewTS(50, 9), NewTS(50, 9)
+	started, stop, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			other.Set(i%50, i%9, i%2 == 0)
+			if i == 0 {
+				close(started)
+			}
+		}
+	}()
+	<-started
+	for i := 0; i < 5000; i++ {
+		b.ToggleMask(other)
+		NewTS(0, 9).AppendTable(other)
+		OrAll(b, other)
+		Combine(other, other, func(x, y byte) byte { return x })
+		OrPadded(b, other)
+		ByteDiff(other, other)
+		Equal(b, other)
+	}
+	close(stop)
+	<-done
+}
@@ -0,0 +1,41 @@
+package bitmaptable
+
+import "github.com/boljen/go-bitmap"
+
+// MarshalColumnar implements Bitmaptable.MarshalColumnar
+func (b *bitmaptable) MarshalColumnar() ([]byte, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	data := make([]byte, headerSize+(b.rows*b.columns+7)/8)
+	putHeader(data, b.rows, b.columns)
+	dst := bitmap.Bitmap(data[headerSize:])
+	b.eachSetBit(func(row, column int) bool {
+		dst.Set(column*b.rows+row, true)
+		return true
+	})
+	return data, nil
+}
+
+// UnmarshalColumnar deserializes a table from the format written by
+// MarshalColumnar. The header is the same as that of Marshal, so the format
+// must be known by the caller.
+func UnmarshalColumnar(data []byte) (Bitmaptable, error) {
+	rows, columns, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)-headerSize != (rows*columns+7)/8 {
+		return nil, ErrIllegalData
+	}
+	b := newNTS(rows, columns)
+	src := bitmap.Bitmap(data[headerSize:])
+	for column := 0; column < columns; column++ {
+		for row := 0; row < rows; row++ {
+			if src.Get(column*rows + row) {
+				b.bitmap.Set(row*b.stride+column, true)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,46 @@
+package bitmaptable
+
+import (
+	"testing"
+
+	"github.com/boljen/go-bitmap"
+)
+
+func TestMarshalColumnar(t *testing.T) {
+	for _, b := range []Bitmaptable{New(11, 6), NewTS(11, 6), NewAligned(11, 6)} {
+		for i := 0; i < 66; i += 4 {
+			b.Set(i/6, i%6, true)
+		}
+		data, err := b.MarshalColumnar()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if len(data) != headerSize+9 {
+			t.Fatal("wrong length", len(data))
+		}
+
+		// A single column can be read from the data without decoding it.
+		cells := bitmap.Bitmap(data[headerSize:])
+		for row := 0; row < 11; row++ {
+			want, _ := b.Get(row, 4)
+			if cells.Get(4*11+row) != want {
+				t.Fatal("wrong value in column at", row)
+			}
+		}
+
+		r, err := UnmarshalColumnar(data)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !Equal(r, b) {
+			t.Fatal("wrong round trip")
+		}
+		if _, err := UnmarshalColumnar(data[:len(data)-1]); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned")
+		}
+		huge := []byte{1, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0}
+		if _, err := UnmarshalColumnar(huge); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned for a forged header", err)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package bitmaptable
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/boljen/go-bitmap"
+)
+
+// EmptyColumns implements Bitmaptable.EmptyColumns
+func (b *bitmaptable) EmptyColumns() []int {
+	used := make([]bool, b.columns)
+	b.eachSetBit(func(row, column int) bool {
+		used[column] = true
+		return true
+	})
+
+	empty := []int{}
+	for column, u := range used {
+		if !u {
+			empty = append(empty, column)
+		}
+	}
+	return empty
+}
+
+// FlipColumn implements Bitmaptable.FlipColumn
+func (b *bitmaptable) FlipColumn(column int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if err := b.checkColumn(column); err != nil {
+		return err
+	}
+	if b.stride == 1 {
+		for i := range b.bitmap {
+			b.bitmap[i] = ^b.bitmap[i]
+		}
+		b.normalize()
+		return nil
+	}
+	for i := column; i < b.rows*b.stride; i += b.stride {
+		b.bitmap[i/8] ^= 1 << uint(i%8)
+	}
+	return nil
+}
+
+// ShiftColumn implements Bitmaptable.ShiftColumn
+func (b *bitmaptable) ShiftColumn(column, k int) (Bitmaptable, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	r := newNTS(b.rows, 1)
+	for row := 0; row < b.rows; row++ {
+		src := row - k
+		if src >= 0 && src < b.rows && b.bitmap.Get(src*b.stride+column) {
+			r.bitmap.Set(row, true)
+		}
+	}
+	return r, nil
+}
+
+// RotateColumn implements Bitmaptable.RotateColumn
+func (b *bitmaptable) RotateColumn(column, k int) (Bitmaptable, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	r := newNTS(b.rows, 1)
+	if b.rows == 0 {
+		return r, nil
+	}
+	k %= b.rows
+	if k < 0 {
+		k += b.rows
+	}
+	for row := 0; row < b.rows; row++ {
+		if b.bitmap.Get(row*b.stride + column) {
+			r.bitmap.Set((row+k)%b.rows, true)
+		}
+	}
+	return r, nil
+}
+
+// ColumnAsBitmap implements Bitmaptable.ColumnAsBitmap
+func (b *bitmaptable) ColumnAsBitmap(column int) (bitmap.Bitmap, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	bm := bitmap.New(b.rows)
+	for row := 0; row < b.rows; row++ {
+		if b.bitmap.Get(row*b.stride + column) {
+			bm.Set(row, true)
+		}
+	}
+	return bm, nil
+}
+
+// AddColumns implements Bitmaptable.AddColumns
+func (b *bitmaptable) AddColumns(n int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrIllegalIndex
+	}
+	columns := b.columns + n
+	if columns <= b.stride {
+		b.columns = columns
+		return nil
+	}
+
+	stride := columns
+	if b.aligned {
+		stride = alignStride(columns)
+	}
+	old, oldStride := b.bitmap, b.stride
+	b.bitmap = newAligned(b.rows * stride)
+	b.surplus = 0
+	b.stride = stride
+	for row := 0; row < b.rows; row++ {
+		for column := 0; column < b.columns; column++ {
+			if old.Get(row*oldStride + column) {
+				b.bitmap.Set(row*stride+column, true)
+			}
+		}
+	}
+	b.columns = columns
+	return nil
+}
+
+// ClearColumn implements Bitmaptable.ClearColumn
+func (b *bitmaptable) ClearColumn(column int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if err := b.checkColumn(column); err != nil {
+		return err
+	}
+	if b.stride == 1 {
+		for i := range b.bitmap {
+			b.bitmap[i] = 0
+		}
+		return nil
+	}
+	for i := column; i < b.rows*b.stride; i += b.stride {
+		b.bitmap[i/8] &^= 1 << uint(i%8)
+	}
+	return nil
+}
+
+// SelectColumns implements Bitmaptable.SelectColumns
+func (b *bitmaptable) SelectColumns(columns []int) (Bitmaptable, error) {
+	for _, column := range columns {
+		if err := b.checkColumn(column); err != nil {
+			return nil, err
+		}
+	}
+	r := newNTS(b.rows, len(columns))
+	for row := 0; row < b.rows; row++ {
+		for i, column := range columns {
+			if b.bitmap.Get(row*b.stride + column) {
+				r.bitmap.Set(row*r.stride+i, true)
+			}
+		}
+	}
+	return r, nil
+}
+
+// ColumnParity implements Bitmaptable.ColumnParity
+func (b *bitmaptable) ColumnParity(column int) (bool, error) {
+	if err := b.checkColumn(column); err != nil {
+		return false, err
+	}
+	var x byte
+	if b.stride == 1 {
+		// The bytes are XOR folded first, so only one popcount is needed.
+		n := (b.rows + 7) / 8
+		for i := 0; i < n-1; i++ {
+			x ^= b.bitmap[i]
+		}
+		if n > 0 {
+			x ^= b.bitmap[n-1] & lastByteMask(b.rows)
+		}
+		return bits.OnesCount8(x)%2 == 1, nil
+	}
+	for i := column; i < b.rows*b.stride; i += b.stride {
+		x ^= b.bitmap[i/8] >> uint(i%8)
+	}
+	return x&1 == 1, nil
+}
+
+// AnyInColumn implements Bitmaptable.AnyInColumn
+func (b *bitmaptable) AnyInColumn(column int) (bool, error) {
+	return b.scanColumn(column, true)
+}
+
+// AllInColumn implements Bitmaptable.AllInColumn
+func (b *bitmaptable) AllInColumn(column int) (bool, error) {
+	found, err := b.scanColumn(column, false)
+	return !found, err
+}
+
+// scanColumn returns whether any row of the provided column holds value,
+// stopping at the first one that does.
+func (b *bitmaptable) scanColumn(column int, value bool) (bool, error) {
+	if err := b.checkColumn(column); err != nil {
+		return false, err
+	}
+	if b.stride == 1 {
+		// Whole bytes are compared against the byte that doesn't hold value
+		// in any bit; the last byte is masked to ignore padding.
+		var none byte
+		if !value {
+			none = 0xff
+		}
+		n := (b.rows + 7) / 8
+		for i := 0; i < n; i++ {
+			v, mask := b.bitmap[i], byte(0xff)
+			if i == n-1 {
+				mask = lastByteMask(b.rows)
+			}
+			if v&mask != none&mask {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for i := column; i < b.rows*b.stride; i += b.stride {
+		if b.bitmap.Get(i) == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ColumnCounts implements Bitmaptable.ColumnCounts
+func (b *bitmaptable) ColumnCounts() []int {
+	counts := make([]int, b.columns)
+	b.eachSetBit(func(row, column int) bool {
+		counts[column]++
+		return true
+	})
+	return counts
+}
+
+// ColumnEntropies implements Bitmaptable.ColumnEntropies
+func (b *bitmaptable) ColumnEntropies() []float64 {
+	entropies := make([]float64, b.columns)
+	for column, n := range b.ColumnCounts() {
+		if n == 0 || n == b.rows {
+			continue
+		}
+		p := float64(n) / float64(b.rows)
+		entropies[column] = -(p*math.Log2(p) + (1-p)*math.Log2(1-p))
+	}
+	return entropies
+}
+
+// ColumnCoOccurrence implements Bitmaptable.ColumnCoOccurrence
+func (b *bitmaptable) ColumnCoOccurrence() [][]int {
+	m := make([][]int, b.columns)
+	for i := range m {
+		m[i] = make([]int, b.columns)
+	}
+	set := make([]int, 0, b.columns)
+	for row := 0; row < b.rows; row++ {
+		set = set[:0]
+		offset := row * b.stride
+		for column := 0; column < b.columns; column++ {
+			if b.bitmap.Get(offset + column) {
+				set = append(set, column)
+			}
+		}
+		for _, i := range set {
+			for _, j := range set {
+				m[i][j]++
+			}
+		}
+	}
+	return m
+}
+
+// ColumnsEqual implements Bitmaptable.ColumnsEqual
+func (b *bitmaptable) ColumnsEqual(x, y int) (bool, error) {
+	if err := b.checkColumn(x); err != nil {
+		return false, err
+	}
+	if err := b.checkColumn(y); err != nil {
+		return false, err
+	}
+	for offset := 0; offset < b.rows*b.stride; offset += b.stride {
+		if b.bitmap.Get(offset+x) != b.bitmap.Get(offset+y) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// RollingOrColumn implements Bitmaptable.RollingOrColumn
+func (b *bitmaptable) RollingOrColumn(column, window int) ([]bool, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	if window < 1 {
+		return nil, ErrIllegalSize
+	}
+	r := make([]bool, b.rows)
+	last := -1
+	for row := range r {
+		if b.bitmap.Get(row*b.stride + column) {
+			last = row
+		}
+		r[row] = last >= 0 && row-last < window
+	}
+	return r, nil
+}
+
+// IsColumnMonotone implements Bitmaptable.IsColumnMonotone
+func (b *bitmaptable) IsColumnMonotone(column int) (bool, error) {
+	if err := b.checkColumn(column); err != nil {
+		return false, err
+	}
+	set := false
+	for offset := column; offset < b.rows*b.stride; offset += b.stride {
+		v := b.bitmap.Get(offset)
+		if set && !v {
+			return false, nil
+		}
+		set = v
+	}
+	return true, nil
+}
+
+// FirstTrueInMonotoneColumn implements Bitmaptable.FirstTrueInMonotoneColumn
+func (b *bitmaptable) FirstTrueInMonotoneColumn(column int) (int, error) {
+	if err := b.checkColumn(column); err != nil {
+		return 0, err
+	}
+	row := sort.Search(b.rows, func(row int) bool {
+		return b.bitmap.Get(row*b.stride + column)
+	})
+	if row == b.rows {
+		return -1, nil
+	}
+	return row, nil
+}
+
+// LongestRunInColumn implements Bitmaptable.LongestRunInColumn
+func (b *bitmaptable) LongestRunInColumn(column int, value bool) (int, int, error) {
+	if err := b.checkColumn(column); err != nil {
+		return 0, 0, err
+	}
+	bestStart, bestLength, start := -1, 0, 0
+	for row := 0; row <= b.rows; row++ {
+		if row < b.rows && b.bitmap.Get(row*b.stride+column) == value {
+			continue
+		}
+		if row-start > bestLength {
+			bestStart, bestLength = start, row-start
+		}
+		start = row + 1
+	}
+	return bestStart, bestLength, nil
+}
+
+// SplitColumns implements Bitmaptable.SplitColumns
+func (b *bitmaptable) SplitColumns() []Bitmaptable {
+	columns := make([]*bitmaptable, b.columns)
+	for i := range columns {
+		columns[i] = newNTS(b.rows, 1)
+	}
+	b.eachSetBit(func(row, column int) bool {
+		columns[column].bitmap.Set(row, true)
+		return true
+	})
+	tables := make([]Bitmaptable, b.columns)
+	for i, c := range columns {
+		tables[i] = c
+	}
+	return tables
+}
+
+// VerticalAndCount implements Bitmaptable.VerticalAndCount
+func (b *bitmaptable) VerticalAndCount(column int) (int, error) {
+	if err := b.checkColumn(column); err != nil {
+		return 0, err
+	}
+	n, above := 0, false
+	for offset := column; offset < b.rows*b.stride; offset += b.stride {
+		v := b.bitmap.Get(offset)
+		if v && above {
+			n++
+		}
+		above = v
+	}
+	return n, nil
+}
+
+// SetBitsInRowRange implements Bitmaptable.SetBitsInRowRange
+func (b *bitmaptable) SetBitsInRowRange(column, startRow, endRow int) ([]int, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	if startRow < 0 || startRow > endRow || endRow > b.rows {
+		return nil, ErrIllegalIndex
+	}
+	rows := []int{}
+	for row := startRow; row < endRow; row++ {
+		if b.bitmap.Get(row*b.stride + column) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// ColumnRuns implements Bitmaptable.ColumnRuns
+func (b *bitmaptable) ColumnRuns(column int) ([]Run, error) {
+	if err := b.checkColumn(column); err != nil {
+		return nil, err
+	}
+	runs := []Run{}
+	for row := 0; row < b.rows; row++ {
+		v := b.bitmap.Get(row*b.stride + column)
+		if n := len(runs); n > 0 && runs[n-1].Value == v {
+			runs[n-1].Length++
+			continue
+		}
+		runs = append(runs, Run{Value: v, Start: row, Length: 1})
+	}
+	return runs, nil
+}
@@ -0,0 +1,672 @@
+package bitmaptable
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEmptyColumns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(20, 7), NewTS(20, 7)} {
+		if c := b.EmptyColumns(); !reflect.DeepEqual(c, []int{0, 1, 2, 3, 4, 5, 6}) {
+			t.Fatal("wrong empty columns", c)
+		}
+		b.Set(0, 1, true)
+		b.Set(13, 4, true)
+		b.Set(19, 6, true)
+		if c := b.EmptyColumns(); !reflect.DeepEqual(c, []int{0, 2, 3, 5}) {
+			t.Fatal("wrong empty columns", c)
+		}
+	}
+}
+
+func TestFlipColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(13, 5), NewTS(13, 5), New(13, 1)} {
+		for i := 0; i < 13*b.Columns(); i += 3 {
+			b.Set(i/b.Columns(), i%b.Columns(), true)
+		}
+		before := b.Data(true)
+		column := b.Columns() / 2
+		if err := b.FlipColumn(column); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 13; row++ {
+			for c := 0; c < b.Columns(); c++ {
+				i := row*b.Columns() + c
+				old := before[i/8]&(1<<uint(i%8)) != 0
+				if v, _ := b.Get(row, c); v != (old != (c == column)) {
+					t.Fatal("wrong value at", row, c)
+				}
+			}
+		}
+		if b.Columns() == 1 && b.Data(false)[1]&0xe0 != 0 {
+			t.Fatal("padding must stay cleared")
+		}
+		if err := b.FlipColumn(b.Columns()); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if err := b.FlipColumn(-1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestShiftColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(6, 3), NewTS(6, 3)} {
+		// Column 1: 1 1 0 1 0 1
+		for _, row := range []int{0, 1, 3, 5} {
+			b.Set(row, 1, true)
+		}
+		b.Set(2, 0, true)
+		b.Set(2, 2, true)
+
+		for k, expected := range map[int][]bool{
+			0:  {true, true, false, true, false, true},
+			1:  {false, true, true, false, true, false},
+			-1: {true, false, true, false, true, false},
+			4:  {false, false, false, false, true, true},
+			-6: {false, false, false, false, false, false},
+		} {
+			r, err := b.ShiftColumn(1, k)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if r.Rows() != 6 || r.Columns() != 1 {
+				t.Fatal("wrong dimensions")
+			}
+			for row, e := range expected {
+				if v, _ := r.Get(row, 0); v != e {
+					t.Fatal("wrong value at row", row, "for shift", k)
+				}
+			}
+		}
+		if _, err := b.ShiftColumn(3, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestColumnAsBitmap(t *testing.T) {
+	for _, b := range []Bitmaptable{New(20, 3), NewTS(20, 3)} {
+		for i := 0; i < 60; i += 7 {
+			b.Set(i/3, i%3, true)
+		}
+		for column := 0; column < 3; column++ {
+			bm, err := b.ColumnAsBitmap(column)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if len(bm) != 3 {
+				t.Fatal("wrong bitmap length")
+			}
+			for row := 0; row < 20; row++ {
+				if v, _ := b.Get(row, column); bm.Get(row) != v {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		if _, err := b.ColumnAsBitmap(3); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestAddColumns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 3), NewTS(10, 3), NewAligned(10, 3)} {
+		b.Set(0, 0, true)
+		b.Set(4, 2, true)
+		b.Set(9, 1, true)
+		if err := b.AddColumns(2); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if b.Columns() != 5 || b.Rows() != 10 {
+			t.Fatal("wrong dimensions")
+		}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 5; column++ {
+				expected := (row == 0 && column == 0) || (row == 4 && column == 2) || (row == 9 && column == 1)
+				if v, err := b.Get(row, column); err != nil || v != expected {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		if err := b.AddColumns(-1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestNewAligned(t *testing.T) {
+	b := NewAligned(10, 3).(*bitmaptable)
+	if b.Stride() != 8 || b.Columns() != 3 || len(b.bitmap) != 10 {
+		t.Fatal("wrong configuration")
+	}
+	for i := 0; i < 30; i += 4 {
+		b.Set(i/3, i%3, true)
+	}
+	for i := 0; i < 30; i++ {
+		if v, _ := b.Get(i/3, i%3); v != (i%4 == 0) {
+			t.Fatal("wrong value at", i/3, i%3)
+		}
+	}
+	if byteIndex, bitIndex, _ := b.Locate(4, 2); byteIndex != 4 || bitIndex != 2 {
+		t.Fatal("rows must be byte aligned")
+	}
+
+	data := &b.bitmap[0]
+	if err := b.AddColumns(5); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if &b.bitmap[0] != data || b.Columns() != 8 || b.Stride() != 8 {
+		t.Fatal("adding columns within the slack mustn't reallocate")
+	}
+	if v, _ := b.Get(0, 3); v {
+		t.Fatal("new columns must be false")
+	}
+
+	b.AddColumns(1)
+	if b.Columns() != 9 || b.Stride() != 16 || len(b.bitmap) != 20 {
+		t.Fatal("wrong configuration after growing beyond the slack")
+	}
+	for i := 0; i < 30; i++ {
+		if v, _ := b.Get(i/3, i%3); v != (i%4 == 0) {
+			t.Fatal("wrong value at", i/3, i%3)
+		}
+	}
+
+	data2, _ := b.Marshal()
+	u, err := Unmarshal(data2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for i := 0; i < 90; i++ {
+		v1, _ := b.Get(i/9, i%9)
+		v2, _ := u.Get(i/9, i%9)
+		if v1 != v2 {
+			t.Fatal("wrong round trip at", i/9, i%9)
+		}
+	}
+	dense := New(10, 9)
+	for i := 0; i < 90; i++ {
+		v, _ := b.Get(i/9, i%9)
+		dense.Set(i/9, i%9, v)
+	}
+	if err := b.ToggleMask(dense); err != nil || b.Count() != 0 {
+		t.Fatal("tables with a different stride must be combined", err)
+	}
+}
+
+func TestClearColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(13, 5), NewTS(13, 3), New(13, 1), NewAligned(13, 5)} {
+		columns := b.Columns()
+		for i := 0; i < 13*columns; i++ {
+			b.Set(i/columns, i%columns, true)
+		}
+		column := columns / 2
+		if err := b.ClearColumn(column); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 13; row++ {
+			for c := 0; c < columns; c++ {
+				if v, _ := b.Get(row, c); v != (c != column) {
+					t.Fatal("wrong value at", row, c)
+				}
+			}
+		}
+		if err := b.ClearColumn(columns); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(9, 5), NewTS(9, 5), NewAligned(9, 5)} {
+		for i := 0; i < 45; i += 4 {
+			b.Set(i/5, i%5, true)
+		}
+		for _, columns := range [][]int{{1, 3}, {4, 0, 2}, {}} {
+			r, err := b.SelectColumns(columns)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if r.Rows() != 9 || r.Columns() != len(columns) {
+				t.Fatal("wrong dimensions")
+			}
+			for row := 0; row < 9; row++ {
+				for i, column := range columns {
+					expected, _ := b.Get(row, column)
+					if v, _ := r.Get(row, i); v != expected {
+						t.Fatal("wrong value at", row, i)
+					}
+				}
+			}
+		}
+		if _, err := b.SelectColumns([]int{0, 5}); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestColumnParity(t *testing.T) {
+	for _, b := range []Bitmaptable{New(21, 3), NewTS(21, 3), New(21, 1), NewAligned(21, 3)} {
+		for _, row := range []int{0, 5, 9, 20} {
+			b.Set(row, 0, true)
+		}
+		if b.Columns() > 1 {
+			for _, row := range []int{1, 2, 20} {
+				b.Set(row, 2, true)
+			}
+		}
+		b.Data(false)[len(b.Data(false))-1] |= 0x80
+
+		if p, err := b.ColumnParity(0); err != nil || p {
+			t.Fatal("even column must have parity false")
+		}
+		b.Set(13, 0, true)
+		if p, _ := b.ColumnParity(0); !p {
+			t.Fatal("odd column must have parity true")
+		}
+		if b.Columns() > 1 {
+			if p, _ := b.ColumnParity(2); !p {
+				t.Fatal("odd column must have parity true")
+			}
+			if p, _ := b.ColumnParity(1); p {
+				t.Fatal("empty column must have parity false")
+			}
+		}
+		if _, err := b.ColumnParity(b.Columns()); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestAnyAllInColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(13, 3), NewTS(13, 3), New(13, 1), NewAligned(13, 3)} {
+		// Column 0 stays empty, the last column becomes full.
+		last := b.Columns() - 1
+		if found, err := b.AnyInColumn(0); err != nil || found {
+			t.Fatal("empty column mustn't have any set")
+		}
+		if all, _ := b.AllInColumn(0); all {
+			t.Fatal("empty column mustn't have all set")
+		}
+
+		for row := 0; row < 12; row++ {
+			b.Set(row, last, true)
+		}
+		// Padding mustn't count as the missing row.
+		b.Data(false)[len(b.Data(false))-1] |= 0x80
+		if found, _ := b.AnyInColumn(last); !found {
+			t.Fatal("partial column must have any set")
+		}
+		if all, _ := b.AllInColumn(last); all {
+			t.Fatal("partial column mustn't have all set")
+		}
+		b.Set(12, last, true)
+		if all, err := b.AllInColumn(last); err != nil || !all {
+			t.Fatal("full column must have all set")
+		}
+		if _, err := b.AnyInColumn(b.Columns()); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.AllInColumn(-1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestColumnCounts(t *testing.T) {
+	for _, b := range []Bitmaptable{New(9, 3), NewTS(9, 3), NewAligned(9, 3)} {
+		for row := 0; row < 9; row++ {
+			b.Set(row, 0, true)
+			b.Set(row, 2, row%3 == 0)
+		}
+		if c := b.ColumnCounts(); !reflect.DeepEqual(c, []int{9, 0, 3}) {
+			t.Fatal("wrong column counts", c)
+		}
+	}
+}
+
+func TestColumnEntropies(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 4), NewTS(10, 4), NewAligned(10, 4)} {
+		for row := 0; row < 10; row++ {
+			b.Set(row, 0, true)
+			b.Set(row, 2, row%2 == 0)
+			b.Set(row, 3, row < 3)
+		}
+		e := b.ColumnEntropies()
+		if len(e) != 4 || e[0] != 0 || e[1] != 0 || e[2] != 1 {
+			t.Fatal("wrong entropies", e)
+		}
+		if math.Abs(e[3]-0.8812908992306927) > 1e-12 {
+			t.Fatal("wrong entropy", e[3])
+		}
+	}
+	if e := New(0, 2).ColumnEntropies(); !reflect.DeepEqual(e, []float64{0, 0}) {
+		t.Fatal("a table without rows must have no entropy", e)
+	}
+}
+
+func TestColumnCoOccurrence(t *testing.T) {
+	for _, b := range []Bitmaptable{New(4, 3), NewTS(4, 3), NewAligned(4, 3)} {
+		b.Set(0, 0, true)
+		b.Set(0, 1, true)
+		b.Set(1, 0, true)
+		b.Set(1, 1, true)
+		b.Set(1, 2, true)
+		b.Set(2, 2, true)
+		b.Set(3, 0, true)
+
+		m := b.ColumnCoOccurrence()
+		want := [][]int{
+			{3, 2, 1},
+			{2, 2, 1},
+			{1, 1, 2},
+		}
+		if !reflect.DeepEqual(m, want) {
+			t.Fatal("wrong co-occurrence", m)
+		}
+		for i := range m {
+			if m[i][i] != b.ColumnCounts()[i] {
+				t.Fatal("diagonal must hold the column counts")
+			}
+			for j := range m {
+				if m[i][j] != m[j][i] {
+					t.Fatal("matrix must be symmetric")
+				}
+			}
+		}
+	}
+}
+
+func TestColumnsEqual(t *testing.T) {
+	for _, b := range []Bitmaptable{New(9, 4), NewTS(9, 4), NewAligned(9, 4)} {
+		for row := 0; row < 9; row += 2 {
+			b.Set(row, 0, true)
+			b.Set(row, 2, true)
+			b.Set(row, 3, true)
+		}
+		b.Set(8, 3, false)
+
+		if eq, err := b.ColumnsEqual(0, 2); err != nil || !eq {
+			t.Fatal("identical columns must be equal", err)
+		}
+		if eq, _ := b.ColumnsEqual(1, 1); !eq {
+			t.Fatal("a column must equal itself")
+		}
+		if eq, _ := b.ColumnsEqual(0, 3); eq {
+			t.Fatal("columns differing in one row mustn't be equal")
+		}
+		if _, err := b.ColumnsEqual(0, 4); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.ColumnsEqual(-1, 0); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestRollingOrColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(8, 2), NewTS(8, 2), NewAligned(8, 2)} {
+		for _, row := range []int{1, 2, 6} {
+			b.Set(row, 1, true)
+		}
+		for _, c := range []struct {
+			window int
+			want   []bool
+		}{
+			{1, []bool{false, true, true, false, false, false, true, false}},
+			{2, []bool{false, true, true, true, false, false, true, true}},
+			{20, []bool{false, true, true, true, true, true, true, true}},
+		} {
+			r, err := b.RollingOrColumn(1, c.window)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !reflect.DeepEqual(r, c.want) {
+				t.Fatal("wrong rolling or", c.window, r)
+			}
+		}
+		if r, _ := b.RollingOrColumn(0, 3); !reflect.DeepEqual(r, make([]bool, 8)) {
+			t.Fatal("an empty column must stay empty", r)
+		}
+		if r, _ := b.RollingOrColumn(0, int(maxInt)); !reflect.DeepEqual(r, make([]bool, 8)) {
+			t.Fatal("a huge window mustn't set rows of an empty column", r)
+		}
+		if _, err := b.RollingOrColumn(2, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.RollingOrColumn(1, 0); err != ErrIllegalSize {
+			t.Fatal("illegal size must be returned")
+		}
+	}
+}
+
+func TestIsColumnMonotone(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 3), NewTS(10, 3), NewAligned(10, 3)} {
+		for row := 4; row < 10; row++ {
+			b.Set(row, 0, true)
+			b.Set(row, 1, row != 7)
+		}
+		if m, err := b.IsColumnMonotone(0); err != nil || !m {
+			t.Fatal("column must be monotone", err)
+		}
+		if m, _ := b.IsColumnMonotone(1); m {
+			t.Fatal("column with a false after a true mustn't be monotone")
+		}
+		if m, _ := b.IsColumnMonotone(2); !m {
+			t.Fatal("an empty column must be monotone")
+		}
+		if _, err := b.IsColumnMonotone(3); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestFirstTrueInMonotoneColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(11, 5), NewTS(11, 5), NewAligned(11, 5)} {
+		for row := 0; row < 11; row++ {
+			b.Set(row, 0, true)
+			b.Set(row, 1, row >= 6)
+			b.Set(row, 2, row == 10)
+			b.Set(row, 4, row%3 == 1)
+		}
+		for column, want := range []int{0, 6, 10, -1} {
+			if row, err := b.FirstTrueInMonotoneColumn(column); err != nil || row != want {
+				t.Fatal("wrong transition row", column, row, err)
+			}
+		}
+
+		// Without the invariant the result is unspecified, but still a row.
+		if row, err := b.FirstTrueInMonotoneColumn(4); err != nil || row < -1 || row >= 11 {
+			t.Fatal("wrong row for a column that isn't monotone", row, err)
+		}
+		if _, err := b.FirstTrueInMonotoneColumn(5); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestLongestRunInColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(16, 3), NewTS(16, 3), NewAligned(16, 3)} {
+		// Column 0 holds runs of 2, 3, 3 and 1 set rows.
+		for _, row := range []int{0, 1, 4, 5, 6, 9, 10, 11, 15} {
+			b.Set(row, 0, true)
+		}
+		for row := 0; row < 16; row++ {
+			b.Set(row, 1, true)
+		}
+		for _, c := range []struct {
+			column        int
+			value         bool
+			start, length int
+		}{
+			{0, true, 4, 3},
+			{0, false, 12, 3},
+			{1, true, 0, 16},
+			{1, false, -1, 0},
+			{2, true, -1, 0},
+		} {
+			start, length, err := b.LongestRunInColumn(c.column, c.value)
+			if err != nil || start != c.start || length != c.length {
+				t.Fatal("wrong run", c.column, c.value, start, length, err)
+			}
+		}
+		if _, _, err := b.LongestRunInColumn(3, true); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestSplitColumns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(13, 4), NewTS(13, 4), NewAligned(13, 4)} {
+		for i := 0; i < 52; i += 3 {
+			b.Set(i/4, i%4, true)
+		}
+		tables := b.SplitColumns()
+		if len(tables) != 4 {
+			t.Fatal("wrong amount of tables", len(tables))
+		}
+		for column, c := range tables {
+			if c.Rows() != 13 || c.Columns() != 1 {
+				t.Fatal("wrong dimensions", c.Rows(), c.Columns())
+			}
+			for row := 0; row < 13; row++ {
+				want, _ := b.Get(row, column)
+				if v, _ := c.Get(row, 0); v != want {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		r, _ := Interleave(tables[1], tables[3])
+		if selected, _ := b.SelectColumns([]int{1, 3}); !Equal(r, selected) {
+			t.Fatal("interleaving split columns must restore them")
+		}
+	}
+}
+
+func TestVerticalAndCount(t *testing.T) {
+	for _, b := range []Bitmaptable{New(12, 2), NewTS(12, 2), NewAligned(12, 2)} {
+		// A run of three rows holds two vertical pairs.
+		for _, row := range []int{0, 1, 4, 6, 7, 8, 11} {
+			b.Set(row, 1, true)
+		}
+		b.Set(5, 0, true)
+		if n, err := b.VerticalAndCount(1); err != nil || n != 3 {
+			t.Fatal("wrong count", n, err)
+		}
+		if n, _ := b.VerticalAndCount(0); n != 0 {
+			t.Fatal("isolated bits mustn't be counted", n)
+		}
+		if _, err := b.VerticalAndCount(2); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestRotateColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(5, 2), NewTS(5, 2), NewAligned(5, 2)} {
+		b.Set(0, 1, true)
+		b.Set(1, 1, true)
+		b.Set(4, 1, true)
+		for _, c := range []struct {
+			k    int
+			want []bool
+		}{
+			{1, []bool{true, true, true, false, false}},
+			{-1, []bool{true, false, false, true, true}},
+			{5, []bool{true, true, false, false, true}},
+			{12, []bool{false, true, true, true, false}},
+			{-7, []bool{false, false, true, true, true}},
+		} {
+			r, err := b.RotateColumn(1, c.k)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if r.Rows() != 5 || r.Columns() != 1 {
+				t.Fatal("wrong dimensions")
+			}
+			for row, want := range c.want {
+				if v, _ := r.Get(row, 0); v != want {
+					t.Fatal("wrong value", c.k, row)
+				}
+			}
+		}
+		if _, err := b.RotateColumn(2, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+	if r, err := New(0, 1).RotateColumn(0, 3); err != nil || r.Rows() != 0 {
+		t.Fatal("rotating a table without rows must work", err)
+	}
+}
+
+func TestSetBitsInRowRange(t *testing.T) {
+	for _, b := range []Bitmaptable{New(20, 3), NewTS(20, 3), NewAligned(20, 3)} {
+		for row := 0; row < 20; row += 4 {
+			b.Set(row, 2, true)
+		}
+		for _, c := range []struct {
+			start, end int
+			want       []int
+		}{
+			{0, 20, []int{0, 4, 8, 12, 16}},
+			{1, 13, []int{4, 8, 12}},
+			{4, 5, []int{4}},
+			{5, 8, []int{}},
+			{7, 7, []int{}},
+		} {
+			rows, err := b.SetBitsInRowRange(2, c.start, c.end)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !reflect.DeepEqual(rows, c.want) {
+				t.Fatal("wrong rows", c.start, c.end, rows)
+			}
+		}
+		for _, r := range [][2]int{{-1, 5}, {5, 4}, {0, 21}} {
+			if _, err := b.SetBitsInRowRange(2, r[0], r[1]); err != ErrIllegalIndex {
+				t.Fatal("illegal index must be returned", r)
+			}
+		}
+		if _, err := b.SetBitsInRowRange(3, 0, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestColumnRuns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(12, 2), NewTS(12, 2), NewAligned(12, 2)} {
+		for _, row := range []int{2, 3, 4, 7, 10, 11} {
+			b.Set(row, 1, true)
+		}
+		runs, err := b.ColumnRuns(1)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		want := []Run{
+			{false, 0, 2},
+			{true, 2, 3},
+			{false, 5, 2},
+			{true, 7, 1},
+			{false, 8, 2},
+			{true, 10, 2},
+		}
+		if !reflect.DeepEqual(runs, want) {
+			t.Fatal("wrong runs", runs)
+		}
+		total := 0
+		for _, r := range runs {
+			total += r.Length
+		}
+		if total != b.Rows() {
+			t.Fatal("runs must cover every row")
+		}
+		if runs, _ := b.ColumnRuns(0); !reflect.DeepEqual(runs, []Run{{false, 0, 12}}) {
+			t.Fatal("an empty column must be a single run", runs)
+		}
+		if _, err := b.ColumnRuns(2); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package bitmaptable
+
+import (
+	"math/bits"
+
+	"github.com/boljen/go-bitmap"
+)
+
+// OrPadded returns the union of the provided tables, which must have the same
+// amount of columns but may differ in rows and stride. The result has the
+// stride of the first table and as many rows as the largest table, rows
+// missing from a table are treated as all false.
+func OrPadded(tables ...Bitmaptable) (Bitmaptable, error) {
+	if len(tables) == 0 {
+		return nil, ErrNoTables
+	}
+	rows, columns, stride := 0, tables[0].Columns(), tables[0].Stride()
+	for _, t := range tables {
+		if t.Columns() != columns {
+			return nil, ErrDimensions
+		}
+		if t.Rows() > rows {
+			rows = t.Rows()
+		}
+	}
+
+	r := newStrided(rows, columns, stride, stride != columns)
+	for _, t := range tables {
+		data := restride(t.SafeData(), t.Rows(), columns, t.Stride(), stride)
+		last := len(data) - 1
+		for i := 0; i < last; i++ {
+			r.bitmap[i] |= data[i]
+		}
+		if last >= 0 {
+			r.bitmap[last] |= data[last] & lastByteMask(t.Rows()*stride)
+		}
+	}
+	r.normalize()
+	return r, nil
+}
+
+// Combine returns a table in which every byte of the data is fn applied to the
+// corresponding bytes of a and b, which must have the same dimensions. The
+// result has the stride of a. Padding bits of the result are cleared.
+func Combine(a, b Bitmaptable, fn func(x, y byte) byte) (Bitmaptable, error) {
+	rows, columns, stride := a.Rows(), a.Columns(), a.Stride()
+	if rows != b.Rows() || columns != b.Columns() {
+		return nil, ErrDimensions
+	}
+	r := newStrided(rows, columns, stride, stride != columns)
+	x, y := a.SafeData(), restride(b.SafeData(), rows, columns, b.Stride(), stride)
+	for i := range r.bitmap {
+		r.bitmap[i] = fn(x[i], y[i])
+	}
+	r.normalize()
+	return r, nil
+}
+
+// SymmetricDifference returns a table in which the cells are set that are set
+// in exactly one of a and b, which must have the same dimensions.
+// It is the set-theoretic name of a per-byte XOR.
+func SymmetricDifference(a, b Bitmaptable) (Bitmaptable, error) {
+	return Combine(a, b, func(x, y byte) byte { return x ^ y })
+}
+
+// AndAll returns the intersection of the provided tables, which must have the
+// same dimensions.
+func AndAll(tables ...Bitmaptable) (Bitmaptable, error) {
+	return reduce(tables, func(x, y byte) byte { return x & y })
+}
+
+// OrAll returns the union of the provided tables, which must have the same
+// dimensions. Use OrPadded for tables that differ in rows.
+func OrAll(tables ...Bitmaptable) (Bitmaptable, error) {
+	return reduce(tables, func(x, y byte) byte { return x | y })
+}
+
+// RollingUnionCount returns for every table i the amount of cells set in the
+// union of tables i-window+1 through i. Windows reaching before the first
+// table only include the tables from the first on. All tables must have the
+// same dimensions.
+func RollingUnionCount(tables []Bitmaptable, window int) ([]int, error) {
+	if len(tables) == 0 {
+		return nil, ErrNoTables
+	}
+	if window < 1 {
+		return nil, ErrIllegalSize
+	}
+	rows, columns := tables[0].Rows(), tables[0].Columns()
+	for _, t := range tables[1:] {
+		if t.Rows() != rows || t.Columns() != columns {
+			return nil, ErrDimensions
+		}
+	}
+	counts := make([]int, len(tables))
+	for i := range tables {
+		start := i - window + 1
+		if start < 0 {
+			start = 0
+		}
+		union, err := OrAll(tables[start : i+1]...)
+		if err != nil {
+			return nil, err
+		}
+		counts[i] = union.Count()
+	}
+	return counts, nil
+}
+
+// reduce returns a table in which every bit of the data is the result of
+// folding fn over the corresponding bits of the tables, which must have the
+// same dimensions. fn must operate on every bit independently. The result has
+// the stride of the first table and is the only allocation; the data of the
+// tables is read in place.
+func reduce(tables []Bitmaptable, fn func(x, y byte) byte) (Bitmaptable, error) {
+	if len(tables) == 0 {
+		return nil, ErrNoTables
+	}
+	rows, columns, stride := tables[0].Rows(), tables[0].Columns(), tables[0].Stride()
+	for _, t := range tables[1:] {
+		if t.Rows() != rows || t.Columns() != columns {
+			return nil, ErrDimensions
+		}
+	}
+	r := newStrided(rows, columns, stride, stride != columns)
+	var (
+		i   int
+		err error
+	)
+	fold := func(tRows, tColumns, tStride int, data []byte) {
+		switch {
+		case tRows != rows || tColumns != columns:
+			err = ErrDimensions
+		case tStride == stride && i == 0:
+			copy(r.bitmap, data)
+		case tStride == stride:
+			for j := range r.bitmap {
+				r.bitmap[j] = fn(r.bitmap[j], data[j])
+			}
+		default:
+			src := bitmap.Bitmap(data)
+			for row := 0; row < rows; row++ {
+				for column := 0; column < columns; column++ {
+					index := row*stride + column
+					v := src.Get(row*tStride + column)
+					if i > 0 {
+						v = fn(bitByte(r.bitmap.Get(index)), bitByte(v))&1 != 0
+					}
+					r.bitmap.Set(index, v)
+				}
+			}
+		}
+	}
+	for ; i < len(tables); i++ {
+		if viewData(tables[i], fold); err != nil {
+			return nil, err
+		}
+	}
+	r.normalize()
+	return r, nil
+}
+
+// bitByte returns 1 for a set bit and 0 otherwise.
+func bitByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// dataViewer is implemented by the tables of this package, which can lend
+// their data to fn without copying it.
+type dataViewer interface {
+	view(fn func(rows, columns, stride int, data []byte))
+}
+
+// view calls fn with the data of the table.
+func (b *bitmaptable) view(fn func(rows, columns, stride int, data []byte)) {
+	fn(b.rows, b.columns, b.stride, b.bitmap)
+}
+
+// viewData calls fn with the dimensions and data of t. The data of tables of
+// this package is lent without being copied, under their lock if they are
+// thread-safe, so fn must not retain it or call methods of t. Other tables
+// are read through SafeData.
+func viewData(t Bitmaptable, fn func(rows, columns, stride int, data []byte)) {
+	if v, ok := t.(dataViewer); ok {
+		v.view(fn)
+		return
+	}
+	fn(t.Rows(), t.Columns(), t.Stride(), t.SafeData())
+}
+
+// ColumnDiffCounts returns for every column the amount of rows in which a and
+// b, which must have the same dimensions, hold a different value.
+func ColumnDiffCounts(a, b Bitmaptable) ([]int, error) {
+	rows, columns := a.Rows(), a.Columns()
+	if rows != b.Rows() || columns != b.Columns() {
+		return nil, ErrDimensions
+	}
+	x, y := bitmap.Bitmap(a.SafeData()), bitmap.Bitmap(b.SafeData())
+	xs, ys := a.Stride(), b.Stride()
+	counts := make([]int, columns)
+	for row := 0; row < rows; row++ {
+		for column := range counts {
+			if x.Get(row*xs+column) != y.Get(row*ys+column) {
+				counts[column]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// Interleave returns a table of two columns, of which column 0 holds the values
+// of a and column 1 those of b. Both must be single column tables with the same
+// amount of rows.
+func Interleave(a, b Bitmaptable) (Bitmaptable, error) {
+	rows := a.Rows()
+	if a.Columns() != 1 || b.Columns() != 1 || b.Rows() != rows {
+		return nil, ErrDimensions
+	}
+	x, y := bitmap.Bitmap(a.SafeData()), bitmap.Bitmap(b.SafeData())
+	xs, ys := a.Stride(), b.Stride()
+	r := newNTS(rows, 2)
+	for row := 0; row < rows; row++ {
+		if x.Get(row * xs) {
+			r.bitmap.Set(row*2, true)
+		}
+		if y.Get(row * ys) {
+			r.bitmap.Set(row*2+1, true)
+		}
+	}
+	return r, nil
+}
+
+// ToggleMask implements Bitmaptable.ToggleMask
+func (b *bitmaptable) ToggleMask(mask Bitmaptable) error {
+	return b.toggleMask(mask.Rows(), mask.Columns(), mask.Stride(), mask.SafeData())
+}
+
+func (b *bitmaptable) toggleMask(rows, columns, stride int, data []byte) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if rows != b.rows || columns != b.columns {
+		return ErrDimensions
+	}
+	data = restride(data, rows, columns, stride, b.stride)
+	for i := range b.bitmap {
+		b.bitmap[i] ^= data[i]
+	}
+	b.normalize()
+	return nil
+}
+
+// Equal returns whether both tables have the same dimensions and hold the
+// same cells. Padding bits are ignored.
+func Equal(a, b Bitmaptable) bool {
+	rows, columns := a.Rows(), a.Columns()
+	if rows != b.Rows() || columns != b.Columns() {
+		return false
+	}
+	if a.Stride() != columns || b.Stride() != columns {
+		for row := 0; row < rows; row++ {
+			for column := 0; column < columns; column++ {
+				x, _ := a.Get(row, column)
+				y, _ := b.Get(row, column)
+				if x != y {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	x, y := a.SafeData(), b.SafeData()
+	n := (rows*columns + 7) / 8
+	if n == 0 {
+		return true
+	}
+	for i := 0; i < n-1; i++ {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	mask := lastByteMask(rows * columns)
+	return x[n-1]&mask == y[n-1]&mask
+}
+
+// EqualTo implements Bitmaptable.EqualTo
+func (b *bitmaptable) EqualTo(other Bitmaptable) bool {
+	return Equal(b, other)
+}
+
+// FirstDifference implements Bitmaptable.FirstDifference
+func (b *bitmaptable) FirstDifference(other Bitmaptable) (int, int, bool, error) {
+	return firstDifference(b, other)
+}
+
+func firstDifference(a, b Bitmaptable) (int, int, bool, error) {
+	rows, columns, stride := a.Rows(), a.Columns(), a.Stride()
+	if rows != b.Rows() || columns != b.Columns() {
+		return 0, 0, false, ErrDimensions
+	}
+	if stride != b.Stride() {
+		for row := 0; row < rows; row++ {
+			for column := 0; column < columns; column++ {
+				x, _ := a.Get(row, column)
+				y, _ := b.Get(row, column)
+				if x != y {
+					return row, column, true, nil
+				}
+			}
+		}
+		return 0, 0, false, nil
+	}
+
+	x, y := a.SafeData(), b.SafeData()
+	n := rows * stride
+	for i := 0; i < (n+7)/8; i++ {
+		d := x[i] ^ y[i]
+		for ; d != 0; d &= d - 1 {
+			index := i*8 + bits.TrailingZeros8(d)
+			if index >= n {
+				break
+			}
+			if column := index % stride; column < columns {
+				return index / stride, column, true, nil
+			}
+		}
+	}
+	return 0, 0, false, nil
+}
+
+// restride returns the data of a table of the provided rows and columns laid
+// out with stride to instead of stride. The data itself is returned if both
+// strides are equal.
+func restride(data []byte, rows, columns, stride, to int) []byte {
+	if stride == to {
+		return data
+	}
+	src, r := bitmap.Bitmap(data), newAligned(rows*to)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			if src.Get(row*stride + column) {
+				r.Set(row*to+column, true)
+			}
+		}
+	}
+	return r
+}
+
+// lastByteMask returns the mask of the bits in the last byte of a bitmap of l
+// bits that aren't padding.
+func lastByteMask(l int) byte {
+	if l%8 == 0 {
+		return 0xff
+	}
+	return byte(1)<<uint(l%8) - 1
+}
@@ -0,0 +1,455 @@
+package bitmaptable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrPadded(t *testing.T) {
+	a := New(10, 3)
+	b := NewTS(20, 3)
+	a.Set(0, 0, true)
+	a.Set(9, 2, true)
+	b.Set(0, 1, true)
+	b.Set(9, 2, true)
+	b.Set(10, 0, true)
+	b.Set(19, 2, true)
+
+	// Dirty the padding of a, which overlaps with row 10 of the result.
+	a.Data(false)[3] |= 0xc0
+
+	r, err := OrPadded(a, b)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if r.Rows() != 20 || r.Columns() != 3 {
+		t.Fatal("wrong dimensions")
+	}
+	for row := 0; row < 20; row++ {
+		for column := 0; column < 3; column++ {
+			va, _ := a.Get(row, column)
+			vb, _ := b.Get(row, column)
+			if v, _ := r.Get(row, column); v != (va || vb) {
+				t.Fatal("wrong value at", row, column)
+			}
+		}
+	}
+
+	c := NewAligned(15, 3)
+	c.Set(12, 1, true)
+	r, err = OrPadded(r, c)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if r.Count() != 6 || r.Stride() != 3 {
+		t.Fatal("tables with a different stride must be merged", r.Count(), r.Stride())
+	}
+	if v, _ := r.Get(12, 1); !v {
+		t.Fatal("wrong value at", 12, 1)
+	}
+	if r, _ := OrPadded(c, a); r.Count() != 3 || r.Stride() != 8 || r.PaddingSet() {
+		t.Fatal("the result must have the stride of the first table")
+	}
+
+	if _, err := OrPadded(a, New(10, 4)); err != ErrDimensions {
+		t.Fatal("dimension error must be returned")
+	}
+	if _, err := OrPadded(); err != ErrNoTables {
+		t.Fatal("no tables error must be returned")
+	}
+}
+
+func TestToggleMask(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		mask := NewTS(10, 5)
+		b.Set(0, 0, true)
+		b.Set(5, 3, true)
+		mask.Set(0, 0, true)
+		mask.Set(7, 1, true)
+		mask.Data(false)[6] |= 0xc0
+
+		if err := b.ToggleMask(mask); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 5; column++ {
+				expected := (row == 5 && column == 3) || (row == 7 && column == 1)
+				if v, _ := b.Get(row, column); v != expected {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		if b.PaddingSet() {
+			t.Fatal("padding must stay cleared")
+		}
+		if err := b.ToggleMask(New(5, 10)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+		if err := b.ToggleMask(b); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := New(10, 5)
+	b := NewTS(10, 5)
+	c := NewAligned(10, 5)
+	for _, x := range []Bitmaptable{a, b, c} {
+		x.Set(0, 0, true)
+		x.Set(7, 3, true)
+	}
+	a.Data(false)[6] |= 0x80
+
+	for _, x := range []Bitmaptable{a, b, c} {
+		for _, y := range []Bitmaptable{a, b, c} {
+			if !Equal(x, y) || !x.EqualTo(y) {
+				t.Fatal("tables must be equal")
+			}
+		}
+	}
+
+	b.Set(9, 4, true)
+	if Equal(a, b) || a.EqualTo(b) || b.EqualTo(a) || c.EqualTo(b) {
+		t.Fatal("tables mustn't be equal")
+	}
+	if Equal(New(10, 5), New(5, 10)) {
+		t.Fatal("tables with different dimensions mustn't be equal")
+	}
+	if !Equal(New(0, 5), New(0, 5)) {
+		t.Fatal("empty tables must be equal")
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewAligned(10, 5)} {
+		a := NewTS(10, 5)
+		for _, x := range []Bitmaptable{a, b} {
+			x.Set(1, 1, true)
+			x.Set(8, 0, true)
+		}
+		b.Data(false)[len(b.Data(false))-1] |= 0x80
+		if _, _, differ, err := b.FirstDifference(a); err != nil || differ {
+			t.Fatal("tables mustn't differ")
+		}
+
+		b.Set(9, 4, true)
+		b.Set(6, 2, true)
+		a.Set(6, 3, true)
+		row, column, differ, err := b.FirstDifference(a)
+		if err != nil || !differ || row != 6 || column != 2 {
+			t.Fatal("wrong first difference", row, column, differ)
+		}
+		if row, column, _, _ := a.FirstDifference(b); row != 6 || column != 2 {
+			t.Fatal("wrong first difference", row, column)
+		}
+		if _, _, _, err := b.FirstDifference(New(5, 10)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		a, b := newFn(10, 5), newFn(10, 5)
+		for i := 0; i < 50; i += 2 {
+			a.Set(i/5, i%5, true)
+		}
+		for i := 0; i < 50; i += 3 {
+			b.Set(i/5, i%5, true)
+		}
+
+		and, err := Combine(a, b, func(x, y byte) byte { return x & y })
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		nand, err := Combine(a, b, func(x, y byte) byte { return ^(x & y) })
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 5; column++ {
+				x, _ := a.Get(row, column)
+				y, _ := b.Get(row, column)
+				if v, _ := and.Get(row, column); v != (x && y) {
+					t.Fatal("wrong and at", row, column)
+				}
+				if v, _ := nand.Get(row, column); v != !(x && y) {
+					t.Fatal("wrong nand at", row, column)
+				}
+			}
+		}
+		if nand.PaddingSet() {
+			t.Fatal("padding must be cleared")
+		}
+		if nand.Count() != 50-and.Count() {
+			t.Fatal("nand must complement and")
+		}
+		if _, err := Combine(a, newFn(5, 10), func(x, y byte) byte { return x }); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		a, b := newFn(7, 9), newFn(7, 9)
+		for i := 0; i < 63; i += 2 {
+			a.Set(i/9, i%9, true)
+		}
+		for i := 0; i < 63; i += 5 {
+			b.Set(i/9, i%9, true)
+		}
+
+		d, err := SymmetricDifference(a, b)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		xor, _ := Combine(a, b, func(x, y byte) byte { return x ^ y })
+		if !Equal(d, xor) {
+			t.Fatal("symmetric difference must equal xor")
+		}
+		union, _ := Combine(a, b, func(x, y byte) byte { return x | y })
+		both, _ := Combine(a, b, func(x, y byte) byte { return x & y })
+		if d.Count() != union.Count()-both.Count() {
+			t.Fatal("symmetric difference must be the union without the intersection")
+		}
+		if r, _ := SymmetricDifference(b, a); !Equal(r, d) {
+			t.Fatal("symmetric difference must be commutative")
+		}
+		if r, _ := SymmetricDifference(a, a); r.Count() != 0 {
+			t.Fatal("symmetric difference with itself must be empty")
+		}
+		if r, _ := SymmetricDifference(a, newFn(7, 9)); !Equal(r, a) {
+			t.Fatal("symmetric difference with the empty table must be the identity")
+		}
+		if r, _ := SymmetricDifference(d, b); !Equal(r, a) {
+			t.Fatal("symmetric difference must be its own inverse")
+		}
+		if _, err := SymmetricDifference(a, newFn(9, 7)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestAndAll(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		tables := []Bitmaptable{newFn(6, 7), newFn(6, 7), newFn(6, 7)}
+		for i, step := range []int{2, 3, 4} {
+			for j := 0; j < 42; j += step {
+				tables[i].Set(j/7, j%7, true)
+			}
+		}
+		r, err := AndAll(tables...)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for i := 0; i < 42; i++ {
+			if v, _ := r.Get(i/7, i%7); v != (i%12 == 0) {
+				t.Fatal("only cells set in every table must remain", i)
+			}
+		}
+		if r, _ := AndAll(tables[1]); !Equal(r, tables[1]) {
+			t.Fatal("the intersection of one table must equal it")
+		}
+		if _, err := AndAll(); err != ErrNoTables {
+			t.Fatal("no tables error must be returned")
+		}
+		if _, err := AndAll(tables[0], newFn(7, 7)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestOrAll(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		tables := []Bitmaptable{newFn(6, 7), newFn(6, 7), newFn(6, 7), newFn(6, 7)}
+		for i, step := range []int{5, 7, 11, 13} {
+			for j := 1; j < 42; j += step {
+				tables[i].Set(j/7, j%7, true)
+			}
+		}
+		r, err := OrAll(tables...)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for i := 0; i < 42; i++ {
+			want := false
+			for _, step := range []int{5, 7, 11, 13} {
+				want = want || (i-1)%step == 0
+			}
+			if v, _ := r.Get(i/7, i%7); v != want {
+				t.Fatal("cells set in any table must be set", i)
+			}
+		}
+		if r.PaddingSet() {
+			t.Fatal("padding must be cleared")
+		}
+		if r, _ := OrAll(tables[2]); !Equal(r, tables[2]) {
+			t.Fatal("the union of one table must equal it")
+		}
+		if _, err := OrAll(); err != ErrNoTables {
+			t.Fatal("no tables error must be returned")
+		}
+		if _, err := OrAll(tables[0], newFn(6, 8)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestReduceAllocations(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		tables := make([]Bitmaptable, 8)
+		for i := range tables {
+			tables[i] = newFn(100, 100)
+		}
+		two := testing.AllocsPerRun(10, func() { OrAll(tables[:2]...) })
+		eight := testing.AllocsPerRun(10, func() { AndAll(tables...) })
+		if two != eight {
+			t.Fatal("only the result may be allocated", two, eight)
+		}
+	}
+}
+
+func TestColumnDiffCounts(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		a, b := newFn(10, 4), New(10, 4)
+		for row := 0; row < 10; row++ {
+			a.Set(row, 0, true)
+			b.Set(row, 0, true)
+			a.Set(row, 1, row%2 == 0)
+			b.Set(row, 3, row < 3)
+		}
+		counts, err := ColumnDiffCounts(a, b)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !reflect.DeepEqual(counts, []int{0, 5, 0, 3}) {
+			t.Fatal("wrong diff counts", counts)
+		}
+		if _, err := ColumnDiffCounts(a, New(10, 5)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		a, b := newFn(9, 1), New(9, 1)
+		for row := 0; row < 9; row++ {
+			a.Set(row, 0, row%2 == 0)
+			b.Set(row, 0, row%3 == 0)
+		}
+		r, err := Interleave(a, b)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if r.Rows() != 9 || r.Columns() != 2 {
+			t.Fatal("wrong dimensions", r.Rows(), r.Columns())
+		}
+		for row := 0; row < 9; row++ {
+			if v, _ := r.Get(row, 0); v != (row%2 == 0) {
+				t.Fatal("column 0 must come from a", row)
+			}
+			if v, _ := r.Get(row, 1); v != (row%3 == 0) {
+				t.Fatal("column 1 must come from b", row)
+			}
+		}
+		if _, err := Interleave(a, New(8, 1)); err != ErrDimensions {
+			t.Fatal("dimension error must be returned for different rows")
+		}
+		if _, err := Interleave(newFn(9, 2), b); err != ErrDimensions {
+			t.Fatal("dimension error must be returned for multiple columns")
+		}
+	}
+}
+
+func TestRollingUnionCount(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		tables := make([]Bitmaptable, 4)
+		for i := range tables {
+			tables[i] = newFn(3, 3)
+		}
+		// Cells per table: {0, 1}, {1, 2}, {5}, {0, 8}.
+		for i, cells := range [][]int{{0, 1}, {1, 2}, {5}, {0, 8}} {
+			for _, c := range cells {
+				tables[i].Set(c/3, c%3, true)
+			}
+		}
+		for _, c := range []struct {
+			window int
+			want   []int
+		}{
+			{1, []int{2, 2, 1, 2}},
+			{2, []int{2, 3, 3, 3}},
+			{3, []int{2, 3, 4, 5}},
+			{10, []int{2, 3, 4, 5}},
+		} {
+			counts, err := RollingUnionCount(tables, c.window)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !reflect.DeepEqual(counts, c.want) {
+				t.Fatal("wrong rolling counts", c.window, counts)
+			}
+		}
+		if _, err := RollingUnionCount(tables, 0); err != ErrIllegalSize {
+			t.Fatal("illegal size must be returned")
+		}
+		if _, err := RollingUnionCount(nil, 1); err != ErrNoTables {
+			t.Fatal("no tables error must be returned")
+		}
+		if _, err := RollingUnionCount(append(tables, newFn(3, 4)), 1); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+	}
+}
+
+func TestMixedStrides(t *testing.T) {
+	a, b := New(6, 5), NewAligned(6, 5)
+	for i := 0; i < 30; i += 3 {
+		a.Set(i/5, i%5, true)
+	}
+	for i := 0; i < 30; i += 4 {
+		b.Set(i/5, i%5, true)
+	}
+	cell := func(i int, fn func(x, y bool) bool) bool {
+		return fn(i%3 == 0, i%4 == 0)
+	}
+	check := func(name string, r Bitmaptable, err error, fn func(x, y bool) bool) {
+		if err != nil {
+			t.Fatal(name, "unexpected error", err)
+		}
+		for i := 0; i < 30; i++ {
+			if v, _ := r.Get(i/5, i%5); v != cell(i, fn) {
+				t.Fatal(name, "wrong value at", i)
+			}
+		}
+	}
+	and := func(x, y bool) bool { return x && y }
+	or := func(x, y bool) bool { return x || y }
+	xor := func(x, y bool) bool { return x != y }
+
+	r, err := Combine(a, b, func(x, y byte) byte { return x & y })
+	check("Combine", r, err, and)
+	r, err = Combine(b, a, func(x, y byte) byte { return x | y })
+	check("Combine", r, err, or)
+	r, err = SymmetricDifference(b, a)
+	check("SymmetricDifference", r, err, xor)
+	r, err = AndAll(a, b)
+	check("AndAll", r, err, and)
+	r, err = OrAll(b, a, NewTimestamped(6, 5, nil))
+	check("OrAll", r, err, or)
+	if counts, err := RollingUnionCount([]Bitmaptable{a, b}, 2); err != nil || counts[1] != r.Count() {
+		t.Fatal("wrong rolling counts", counts, err)
+	}
+
+	mask := NewTS(6, 5)
+	if err := mask.ToggleMask(a); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := mask.ToggleMask(b); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	check("ToggleMask", mask, nil, xor)
+}
@@ -0,0 +1,94 @@
+package bitmaptable
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// Count implements Bitmaptable.Count
+func (b *bitmaptable) Count() int {
+	if b.stride == b.columns {
+		return b.countRange(0, b.rows*b.columns)
+	}
+	count := 0
+	for row := 0; row < b.rows; row++ {
+		count += b.rowPopcount(row)
+	}
+	return count
+}
+
+// CountZeros implements Bitmaptable.CountZeros
+func (b *bitmaptable) CountZeros() int {
+	if b.stride == b.columns {
+		return b.countZeroRange(0, b.rows*b.columns)
+	}
+	count := 0
+	for offset := 0; offset < b.rows*b.stride; offset += b.stride {
+		count += b.countZeroRange(offset, offset+b.columns)
+	}
+	return count
+}
+
+// countZeroRange returns the amount of clear bits in the flat range
+// [start, end).
+func (b *bitmaptable) countZeroRange(start, end int) int {
+	count := 0
+	for ; start < end && start%8 != 0; start++ {
+		if !b.bitmap.Get(start) {
+			count++
+		}
+	}
+	for ; start+8 <= end; start += 8 {
+		count += bits.OnesCount8(^b.bitmap[start/8])
+	}
+	if start < end {
+		// The bits beyond end are masked, so padding isn't counted.
+		count += bits.OnesCount8(^b.bitmap[start/8] & lastByteMask(end-start))
+	}
+	return count
+}
+
+// Density implements Bitmaptable.Density
+func (b *bitmaptable) Density() float64 {
+	cells := b.rows * b.columns
+	if cells == 0 {
+		return 0
+	}
+	return float64(b.Count()) / float64(cells)
+}
+
+// ApproxCount implements Bitmaptable.ApproxCount
+func (b *bitmaptable) ApproxCount(sampleBytes int, rng *rand.Rand) int {
+	n := (b.rows*b.stride + 7) / 8
+	if sampleBytes >= n {
+		return b.Count()
+	}
+	if sampleBytes < 1 {
+		sampleBytes = 1
+	}
+	// Padding bits may have been set through Data, so they are masked out of
+	// every sampled byte.
+	set := 0
+	for i := 0; i < sampleBytes; i++ {
+		j := rng.Intn(n)
+		set += bits.OnesCount8(b.bitmap[j] & b.cellMask(j))
+	}
+	return int(math.Round(float64(set) * float64(n) / float64(sampleBytes)))
+}
+
+// cellMask returns the mask of the bits of byte i of the data that hold cells
+// rather than padding.
+func (b *bitmaptable) cellMask(i int) byte {
+	end := b.rows * b.stride
+	if b.stride == b.columns && (i+1)*8 <= end {
+		return 0xff
+	}
+	var mask byte
+	for bit := 0; bit < 8; bit++ {
+		if index := i*8 + bit; index < end && index%b.stride < b.columns {
+			mask |= 1 << uint(bit)
+		}
+	}
+	return mask
+}
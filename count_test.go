@@ -0,0 +1,95 @@
+package bitmaptable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewAligned(10, 5)} {
+		if b.Count() != 0 {
+			t.Fatal("wrong count")
+		}
+		for i := 0; i < 50; i += 3 {
+			b.Set(i/5, i%5, true)
+		}
+		b.Data(false)[len(b.Data(false))-1] |= 0x80
+		if b.Count() != 17 {
+			t.Fatal("wrong count", b.Count())
+		}
+	}
+}
+
+func TestDensity(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 4), NewTS(10, 4)} {
+		if b.Density() != 0 {
+			t.Fatal("empty table must have density 0")
+		}
+		for i := 0; i < 40; i += 2 {
+			b.Set(i/4, i%4, true)
+		}
+		if b.Density() != 0.5 {
+			t.Fatal("half full table must have density 0.5")
+		}
+		for i := 1; i < 40; i += 2 {
+			b.Set(i/4, i%4, true)
+		}
+		if b.Density() != 1 {
+			t.Fatal("full table must have density 1")
+		}
+	}
+	if New(0, 4).Density() != 0 || New(4, 0).Density() != 0 {
+		t.Fatal("table without cells must have density 0")
+	}
+}
+
+func TestApproxCount(t *testing.T) {
+	for _, b := range []Bitmaptable{New(1000, 100), NewTS(1000, 100), NewAligned(1000, 97)} {
+		fill := rand.New(rand.NewSource(1))
+		for row := 0; row < b.Rows(); row++ {
+			for column := 0; column < b.Columns(); column++ {
+				b.Set(row, column, fill.Float64() < 0.3)
+			}
+		}
+		count := b.Count()
+		estimate := b.ApproxCount(2000, rand.New(rand.NewSource(2)))
+		if math.Abs(float64(estimate-count)) > 0.05*float64(count) {
+			t.Fatal("estimate is too far off", estimate, count)
+		}
+		if n := b.ApproxCount(len(b.Data(false)), rand.New(rand.NewSource(2))); n != count {
+			t.Fatal("sampling all data must be exact", n, count)
+		}
+	}
+	// Dirty padding must not be counted.
+	dense, aligned := New(3, 5), NewAligned(3, 5)
+	dense.Data(false)[1] = 0x80
+	for i := range aligned.Data(false) {
+		aligned.Data(false)[i] = 0xe0
+	}
+	for _, b := range []Bitmaptable{dense, aligned} {
+		if n := b.ApproxCount(len(b.Data(false))-1, rand.New(rand.NewSource(3))); n != 0 {
+			t.Fatal("padding bits must be ignored", n)
+		}
+	}
+	if n := New(0, 5).ApproxCount(10, rand.New(rand.NewSource(1))); n != 0 {
+		t.Fatal("a table without cells must be empty", n)
+	}
+}
+
+func TestCountZeros(t *testing.T) {
+	for _, dim := range [][2]int{{10, 5}, {8, 8}, {3, 3}, {1, 1}, {0, 4}, {7, 13}} {
+		rows, columns := dim[0], dim[1]
+		for _, b := range []Bitmaptable{New(rows, columns), NewTS(rows, columns), NewAligned(rows, columns)} {
+			if n := b.CountZeros(); n != rows*columns {
+				t.Fatal("every cell of an empty table must be zero", dim, n)
+			}
+			for i := 0; i < rows*columns; i += 3 {
+				b.Set(i/columns, i%columns, true)
+			}
+			if b.Count()+b.CountZeros() != rows*columns {
+				t.Fatal("set and zero cells must add up to all cells", dim, b.Count(), b.CountZeros())
+			}
+		}
+	}
+}
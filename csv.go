@@ -0,0 +1,77 @@
+package bitmaptable
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{"row", "column"}
+
+// WriteCSV implements Bitmaptable.WriteCSV
+func (b *bitmaptable) WriteCSV(w io.Writer) error {
+	if b.closed {
+		return ErrClosed
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	var err error
+	record := make([]string, 2)
+	b.eachSetBit(func(row, column int) bool {
+		record[0] = strconv.Itoa(row)
+		record[1] = strconv.Itoa(column)
+		err = cw.Write(record)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV creates a new Bitmaptable instance with the bits set that are listed
+// in CSV data as written by WriteCSV.
+func ReadCSV(r io.Reader, rows, columns int) (Bitmaptable, error) {
+	b := newNTS(rows, columns)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.ReuseRecord = true
+
+	if _, err := cr.Read(); err == io.EOF {
+		return nil, ErrIllegalData
+	} else if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return b, nil
+		} else if err != nil {
+			return nil, err
+		}
+		row, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, csvError(cr, err)
+		}
+		column, err := strconv.Atoi(record[1])
+		if err != nil {
+			return nil, csvError(cr, err)
+		}
+		if row < 0 || column < 0 {
+			return nil, csvError(cr, ErrIllegalIndex)
+		}
+		if err := b.Set(row, column, true); err != nil {
+			return nil, csvError(cr, err)
+		}
+	}
+}
+
+// csvError annotates err with the line of the last record read by cr.
+func csvError(cr *csv.Reader, err error) error {
+	line, _ := cr.FieldPos(0)
+	return fmt.Errorf("Bitmaptable: Malformed CSV on line %d: %w", line, err)
+}
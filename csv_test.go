@@ -0,0 +1,47 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		b.Set(0, 1, true)
+		b.Set(4, 4, true)
+		b.Set(9, 0, true)
+
+		buf := new(bytes.Buffer)
+		if err := b.WriteCSV(buf); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if buf.String() != "row,column\n0,1\n4,4\n9,0\n" {
+			t.Fatal("wrong csv", buf.String())
+		}
+
+		r, err := ReadCSV(buf, 10, 5)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !bytes.Equal(r.Data(false), b.Data(false)) {
+			t.Fatal("wrong round trip")
+		}
+	}
+}
+
+func TestReadCSVMalformed(t *testing.T) {
+	if _, err := ReadCSV(strings.NewReader("row,column\n0,1\n1,x\n"), 10, 5); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatal("malformed line must be reported", err)
+	}
+	if _, err := ReadCSV(strings.NewReader("row,column\n0,1,2\n"), 10, 5); err == nil {
+		t.Fatal("malformed line must be reported")
+	}
+	if _, err := ReadCSV(strings.NewReader("row,column\n10,1\n"), 10, 5); !errors.Is(err, ErrIllegalIndex) {
+		t.Fatal("illegal index must be returned", err)
+	}
+	if _, err := ReadCSV(strings.NewReader(""), 10, 5); err == nil {
+		t.Fatal("missing header must be reported")
+	}
+}
@@ -0,0 +1,52 @@
+package bitmaptable
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// SetBitCursor iterates over the set cells of a table in row-major order
+// without callbacks or allocations.
+//
+// The cursor caches the byte it is positioned in, so changes to that byte
+// made after it was reached aren't observed.
+type SetBitCursor struct {
+	b       *bitmaptable
+	mu      sync.Locker // Held while advancing, nil if not thread-safe.
+	i       int         // Index of the current byte.
+	pending byte        // Set bits of the current byte not visited yet.
+}
+
+// Cursor implements Bitmaptable.Cursor
+func (b *bitmaptable) Cursor() *SetBitCursor {
+	return &SetBitCursor{b: b, i: -1}
+}
+
+// Next advances the cursor to the next set cell and returns its coordinates.
+// It returns false once all set cells have been visited.
+func (c *SetBitCursor) Next() (row, column int, ok bool) {
+	if c.mu != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	b := c.b
+	for {
+		for c.pending == 0 {
+			if c.i >= len(b.bitmap)-1 {
+				c.i = len(b.bitmap)
+				return 0, 0, false
+			}
+			c.i++
+			c.pending = b.bitmap[c.i]
+		}
+		index := c.i*8 + bits.TrailingZeros8(c.pending)
+		c.pending &= c.pending - 1
+		if index >= b.rows*b.stride {
+			c.i, c.pending = len(b.bitmap), 0
+			return 0, 0, false
+		}
+		if column := index % b.stride; column < b.columns {
+			return index / b.stride, column, true
+		}
+	}
+}
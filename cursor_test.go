@@ -0,0 +1,33 @@
+package bitmaptable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetBitCursor(t *testing.T) {
+	for _, b := range []Bitmaptable{New(30, 7), NewTS(30, 7), NewAligned(30, 7)} {
+		var expected []Coord
+		for _, i := range []int{0, 6, 7, 8, 63, 64, 100, 209} {
+			b.Set(i/7, i%7, true)
+			expected = append(expected, Coord{i / 7, i % 7})
+		}
+		b.Data(false)[len(b.Data(false))-1] |= 0x80
+
+		var coords []Coord
+		c := b.Cursor()
+		for row, column, ok := c.Next(); ok; row, column, ok = c.Next() {
+			coords = append(coords, Coord{row, column})
+		}
+		if !reflect.DeepEqual(coords, expected) {
+			t.Fatal("wrong set cells", coords)
+		}
+		if _, _, ok := c.Next(); ok {
+			t.Fatal("exhausted cursor must stay exhausted")
+		}
+	}
+
+	if _, _, ok := New(0, 0).Cursor().Next(); ok {
+		t.Fatal("empty table must have no set cells")
+	}
+}
@@ -0,0 +1,106 @@
+package bitmaptable
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// ByteChange is the new value of a single byte of the data of a table.
+type ByteChange struct {
+	Offset int
+	Value  byte
+}
+
+// ByteDiff returns the bytes of the data of new that differ from old, which
+// must have the same dimensions. The offsets refer to the data of old, new is
+// laid out with the stride of old first if its stride differs.
+func ByteDiff(old, new Bitmaptable) ([]ByteChange, error) {
+	rows, columns, stride := old.Rows(), old.Columns(), old.Stride()
+	if rows != new.Rows() || columns != new.Columns() {
+		return nil, ErrDimensions
+	}
+	o, n := old.SafeData(), restride(new.SafeData(), rows, columns, new.Stride(), stride)
+	mask := lastByteMask(rows * stride)
+	changes := []ByteChange{}
+	for i := range n {
+		x, y := o[i], n[i]
+		if i == len(n)-1 {
+			x, y = x&mask, y&mask
+		}
+		if x != y {
+			changes = append(changes, ByteChange{Offset: i, Value: y})
+		}
+	}
+	return changes, nil
+}
+
+// ApplyByteDiff applies changes returned by ByteDiff to base. All offsets are
+// validated before any change is applied.
+func ApplyByteDiff(base Bitmaptable, changes []ByteChange) error {
+	columns, stride := base.Columns(), base.Stride()
+	l := base.Rows() * stride
+	size := (l + 7) / 8
+	for _, c := range changes {
+		if c.Offset < 0 || c.Offset >= size {
+			return ErrIllegalIndex
+		}
+	}
+	for _, c := range changes {
+		for bit := 0; bit < 8; bit++ {
+			i := c.Offset*8 + bit
+			if i >= l {
+				break
+			}
+			if i%stride >= columns {
+				continue
+			}
+			if err := base.Set(i/stride, i%stride, c.Value&(1<<uint(bit)) != 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyChanges implements Bitmaptable.ApplyChanges
+func (b *bitmaptable) ApplyChanges(changes map[Coord]bool) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	for c := range changes {
+		if !b.ValidIndex(c.Row, c.Column) {
+			return ErrIllegalIndex
+		}
+	}
+	for c, v := range changes {
+		b.bitmap.Set(c.Row*b.stride+c.Column, v)
+	}
+	return nil
+}
+
+// ChangesSince implements Bitmaptable.ChangesSince
+func (b *bitmaptable) ChangesSince(snap []byte) ([]Coord, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if len(snap) != len(b.bitmap) {
+		return nil, fmt.Errorf("%w: need %d bytes, got %d", ErrIllegalData, len(b.bitmap), len(snap))
+	}
+	changes := []Coord{}
+	end := b.rows * b.stride
+	for i, v := range b.bitmap {
+		// Only the bytes that differ are inspected bit by bit.
+		x := v ^ snap[i]
+		for x != 0 {
+			bit := i*8 + bits.TrailingZeros8(x)
+			x &= x - 1
+			if bit >= end {
+				break
+			}
+			if column := bit % b.stride; column < b.columns {
+				changes = append(changes, Coord{Row: bit / b.stride, Column: column})
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,122 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestByteDiff(t *testing.T) {
+	old := New(20, 7)
+	for i := 0; i < 140; i += 5 {
+		old.Set(i/7, i%7, true)
+	}
+	new := NewTS(20, 7)
+	copy(new.Data(false), old.Data(false))
+	new.Set(0, 0, false)
+	new.Set(0, 1, true)
+	new.Set(9, 3, true)
+	new.Set(19, 6, true)
+
+	changes, err := ByteDiff(old, new)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(changes) != 3 {
+		t.Fatal("wrong amount of changes", changes)
+	}
+
+	// Dirty padding must not show up as a change.
+	old.Data(false)[17] |= 0xf0
+	if c, _ := ByteDiff(old, new); len(c) != 3 {
+		t.Fatal("padding must be ignored", c)
+	}
+	old.Data(false)[17] &= 0x0f
+
+	base := NewTS(20, 7)
+	copy(base.Data(false), old.Data(false))
+	if err := ApplyByteDiff(base, changes); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !bytes.Equal(base.Data(false), new.Data(false)) {
+		t.Fatal("old + diff must equal new")
+	}
+
+	aligned := NewAligned(20, 7)
+	for i := 0; i < 140; i++ {
+		v, _ := new.Get(i/7, i%7)
+		aligned.Set(i/7, i%7, v)
+	}
+	if c, err := ByteDiff(old, aligned); err != nil || !reflect.DeepEqual(c, changes) {
+		t.Fatal("tables with different strides must be compared by cell", c, err)
+	}
+
+	if _, err := ByteDiff(old, New(7, 20)); err != ErrDimensions {
+		t.Fatal("dimension error must be returned")
+	}
+	if err := ApplyByteDiff(base, []ByteChange{{0, 0}, {18, 1}}); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if v, _ := base.Get(0, 1); !v {
+		t.Fatal("invalid diffs mustn't be partially applied")
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewTimestamped(10, 5, nil)} {
+		b.Set(1, 1, true)
+		b.Set(2, 2, true)
+		err := b.ApplyChanges(map[Coord]bool{
+			{1, 1}: false,
+			{3, 4}: true,
+			{9, 0}: true,
+			{2, 2}: true,
+		})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 5; column++ {
+				expected := (row == 2 && column == 2) || (row == 3 && column == 4) || (row == 9 && column == 0)
+				if v, _ := b.Get(row, column); v != expected {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+
+		if err := b.ApplyChanges(map[Coord]bool{{0, 0}: true, {10, 0}: true}); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if v, _ := b.Get(0, 0); v {
+			t.Fatal("changes mustn't be partially applied")
+		}
+	}
+}
+
+func TestChangesSince(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewAligned(10, 5)} {
+		b.Set(2, 2, true)
+		b.Set(7, 1, true)
+		snap := b.SafeData()
+		if changes, err := b.ChangesSince(snap); err != nil || len(changes) != 0 {
+			t.Fatal("an unchanged table mustn't have changes", changes, err)
+		}
+
+		b.Set(0, 0, true)
+		b.Set(2, 2, false)
+		b.Set(9, 4, true)
+		b.Set(7, 1, true)
+		changes, err := b.ChangesSince(snap)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !reflect.DeepEqual(changes, []Coord{{0, 0}, {2, 2}, {9, 4}}) {
+			t.Fatal("wrong changes", changes)
+		}
+
+		if _, err := b.ChangesSince(snap[1:]); !errors.Is(err, ErrIllegalData) {
+			t.Fatal("illegal data must be returned", err)
+		}
+	}
+}
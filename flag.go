@@ -0,0 +1,45 @@
+package bitmaptable
+
+import "strconv"
+
+// Flag is a boolean cell value. Domain specific flag types can be declared on
+// top of it and accessed through a typed Column.
+//
+//	type Gender bitmaptable.Flag
+//
+//	const (
+//	    MAN   Gender = false
+//	    WOMAN Gender = true
+//	)
+type Flag bool
+
+// String implements fmt.Stringer
+func (f Flag) String() string {
+	return strconv.FormatBool(bool(f))
+}
+
+// Column is a typed accessor for a single column of a bitmap table, so that
+// values of one column can't be mixed up with values of another.
+type Column[T ~bool] struct {
+	table  Bitmaptable
+	column int
+}
+
+// NewColumn creates a typed accessor for the provided column of the table.
+func NewColumn[T ~bool](table Bitmaptable, column int) (Column[T], error) {
+	if column < 0 || column >= table.Columns() {
+		return Column[T]{}, ErrIllegalIndex
+	}
+	return Column[T]{table: table, column: column}, nil
+}
+
+// Get gets the value of the column for the provided row.
+func (c Column[T]) Get(row int) (T, error) {
+	v, err := c.table.Get(row, c.column)
+	return T(v), err
+}
+
+// Set sets the value of the column for the provided row.
+func (c Column[T]) Set(row int, value T) error {
+	return c.table.Set(row, c.column, bool(value))
+}
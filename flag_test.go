@@ -0,0 +1,57 @@
+package bitmaptable
+
+import "testing"
+
+type gender Flag
+
+const (
+	man   gender = false
+	woman gender = true
+)
+
+type death Flag
+
+const (
+	alive death = false
+	dead  death = true
+)
+
+func TestColumn(t *testing.T) {
+	b := New(10, 2)
+	genders, err := NewColumn[gender](b, 0)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	deaths, err := NewColumn[death](b, 1)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	genders.Set(3, woman)
+	deaths.Set(4, dead)
+
+	if g, err := genders.Get(3); err != nil || g != woman {
+		t.Fatal("wrong gender")
+	}
+	if g, _ := genders.Get(4); g != man {
+		t.Fatal("wrong gender")
+	}
+	if d, _ := deaths.Get(3); d != alive {
+		t.Fatal("wrong death")
+	}
+	if d, _ := deaths.Get(4); d != dead {
+		t.Fatal("wrong death")
+	}
+	if err := deaths.Set(10, dead); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if _, err := NewColumn[death](b, 2); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+}
+
+func TestFlagString(t *testing.T) {
+	if Flag(true).String() != "true" || Flag(false).String() != "false" {
+		t.Fatal("wrong string")
+	}
+}
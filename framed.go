@@ -0,0 +1,46 @@
+package bitmaptable
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// WriteFramed implements Bitmaptable.WriteFramed
+func (b *bitmaptable) WriteFramed(w io.Writer) (int, error) {
+	data, err := b.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(data)) > 1<<32-1 {
+		return 0, ErrOverflow
+	}
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	return w.Write(frame)
+}
+
+// ReadFramed reads a single table written by WriteFramed from r, consuming
+// exactly its frame so that consecutive tables can be read from a stream.
+func ReadFramed(r io.Reader) (Bitmaptable, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, readError(err)
+	}
+	size := int64(binary.BigEndian.Uint32(prefix[:]))
+	lr := &io.LimitedReader{R: r, N: size}
+	rows, columns, err := readHeader(lr)
+	if err != nil {
+		return nil, err
+	}
+	// The header is validated against the frame before the data is
+	// allocated, so a corrupt header can't cause a huge allocation.
+	if int64(headerSize)+int64((rows*columns+7)/8) != size {
+		return nil, ErrIllegalData
+	}
+	b := newNTS(rows, columns)
+	if _, err := io.ReadFull(lr, b.bitmap); err != nil {
+		return nil, readError(err)
+	}
+	return b, nil
+}
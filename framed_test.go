@@ -0,0 +1,56 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFramed(t *testing.T) {
+	a := New(10, 5)
+	a.Set(0, 0, true)
+	a.Set(9, 4, true)
+	for _, b := range []Bitmaptable{New(3, 17), NewTS(3, 17), NewAligned(3, 17)} {
+		b.Set(1, 16, true)
+		b.Set(2, 3, true)
+
+		buf := new(bytes.Buffer)
+		n, err := a.WriteFramed(buf)
+		if err != nil || n != 4+headerSize+7 {
+			t.Fatal("wrong write", n, err)
+		}
+		if n, err := b.WriteFramed(buf); err != nil || n != 4+headerSize+7 {
+			t.Fatal("wrong write", n, err)
+		}
+		if buf.Len() != 2*n {
+			t.Fatal("wrong amount of bytes written")
+		}
+
+		for _, want := range []Bitmaptable{a, b} {
+			r, err := ReadFramed(buf)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !Equal(r, want) {
+				t.Fatal("wrong round trip")
+			}
+		}
+		if buf.Len() != 0 {
+			t.Fatal("every frame must be consumed")
+		}
+		if _, err := ReadFramed(buf); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned", err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	a.WriteFramed(buf)
+	data := buf.Bytes()
+	data[3]--
+	if _, err := ReadFramed(bytes.NewReader(data)); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned for a wrong length", err)
+	}
+	data[3]++
+	if _, err := ReadFramed(bytes.NewReader(data[:len(data)-1])); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned for a truncated frame", err)
+	}
+}
@@ -0,0 +1,23 @@
+package bitmaptable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoLiteral implements Bitmaptable.GoLiteral
+func (b *bitmaptable) GoLiteral(varName string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s, err := bitmaptable.NewFromCoords(%d, %d, []bitmaptable.Coord{", varName, b.rows, b.columns)
+	first := true
+	b.eachSetBit(func(row, column int) bool {
+		if !first {
+			sb.WriteString(", ")
+		}
+		first = false
+		fmt.Fprintf(&sb, "{Row: %d, Column: %d}", row, column)
+		return true
+	})
+	sb.WriteString("})\nif err != nil {\n\tpanic(err)\n}")
+	return sb.String()
+}
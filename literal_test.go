@@ -0,0 +1,56 @@
+package bitmaptable
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestGoLiteral(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		coords := []Coord{{0, 1}, {3, 4}, {9, 0}}
+		for _, c := range coords {
+			b.Set(c.Row, c.Column, true)
+		}
+
+		literal := b.GoLiteral("bm")
+		expected := "bm, err := bitmaptable.NewFromCoords(10, 5, []bitmaptable.Coord{" +
+			"{Row: 0, Column: 1}, {Row: 3, Column: 4}, {Row: 9, Column: 0}})\n" +
+			"if err != nil {\n\tpanic(err)\n}"
+		if literal != expected {
+			t.Fatal("wrong literal", literal)
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc f() {\n"+literal+"\n}", 0)
+		if err != nil {
+			t.Fatal("literal must be valid Go", err)
+		}
+		var parsed []Coord
+		ast.Inspect(f, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.CompositeLit); ok && lit.Type == nil {
+				row, _ := strconv.Atoi(lit.Elts[0].(*ast.KeyValueExpr).Value.(*ast.BasicLit).Value)
+				column, _ := strconv.Atoi(lit.Elts[1].(*ast.KeyValueExpr).Value.(*ast.BasicLit).Value)
+				parsed = append(parsed, Coord{row, column})
+			}
+			return true
+		})
+		if !reflect.DeepEqual(parsed, coords) {
+			t.Fatal("wrong coordinates in literal", parsed)
+		}
+
+		r, err := NewFromCoords(10, 5, parsed)
+		if err != nil || !Equal(r, b) {
+			t.Fatal("literal doesn't recreate the table")
+		}
+	}
+
+	if _, err := NewFromCoords(10, 5, []Coord{{10, 0}}); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if l := New(2, 2).GoLiteral("x"); l != "x, err := bitmaptable.NewFromCoords(2, 2, []bitmaptable.Coord{})\nif err != nil {\n\tpanic(err)\n}" {
+		t.Fatal("wrong literal", l)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package bitmaptable
+
+import "errors"
+
+// OpenMmapReadOnly isn't supported on this platform.
+func OpenMmapReadOnly(path string) (Bitmaptable, error) {
+	return nil, errors.New("Bitmaptable: Memory mapping isn't supported on this platform")
+}
@@ -0,0 +1,69 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package bitmaptable
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenMmapReadOnly opens a table file written with Marshal by mapping it
+// read-only into memory, so that multiple processes can share it through the
+// page cache without loading it. Mutations of the returned table return
+// ErrReadOnly and SortRows has no effect. The data returned by Data(false)
+// must not be written to.
+//
+// The returned table implements io.Closer; Close and Free unmap the file.
+func OpenMmapReadOnly(path string) (Bitmaptable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < headerSize {
+		return nil, ErrIllegalData
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, columns, err := parseHeader(data)
+	if err == nil && len(data)-headerSize != (rows*columns+7)/8 {
+		err = ErrIllegalData
+	}
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	return &mmapped{&bitmaptable{
+		rows:     rows,
+		columns:  columns,
+		stride:   columns,
+		bitmap:   data[headerSize:],
+		readOnly: true,
+		release:  func() error { return syscall.Munmap(data) },
+	}}, nil
+}
+
+// mmapped is a read-only table backed by a memory mapped file.
+type mmapped struct {
+	*bitmaptable
+}
+
+// Close implements io.Closer
+func (m *mmapped) Close() error {
+	var err error
+	if m.release != nil {
+		err = m.release()
+		m.release = nil
+	}
+	m.Free()
+	return err
+}
@@ -0,0 +1,57 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package bitmaptable
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenMmapReadOnly(t *testing.T) {
+	b := New(100, 7)
+	for i := 0; i < 700; i += 9 {
+		b.Set(i/7, i%7, true)
+	}
+	data, _ := b.Marshal()
+	path := filepath.Join(t.TempDir(), "table")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := OpenMmapReadOnly(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !Equal(m, b) || m.Count() != b.Count() {
+		t.Fatal("mapped table doesn't match")
+	}
+	if err := m.Set(0, 0, true); err != ErrReadOnly {
+		t.Fatal("read-only error must be returned")
+	}
+	if err := m.FlipRow(3); err != ErrReadOnly {
+		t.Fatal("read-only error must be returned")
+	}
+	m.SortRows(func(a, b []bool) bool { return a[0] && !b[0] })
+	if !Equal(m, b) {
+		t.Fatal("read-only table mustn't be sorted")
+	}
+
+	if err := m.(io.Closer).Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if _, err := m.Get(0, 0); err != ErrClosed {
+		t.Fatal("closed error must be returned")
+	}
+	m.Free()
+
+	os.WriteFile(path, data[:len(data)-1], 0600)
+	if _, err := OpenMmapReadOnly(path); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned")
+	}
+	if _, err := OpenMmapReadOnly(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("missing file must return an error")
+	}
+}
@@ -0,0 +1,79 @@
+package bitmaptable
+
+import "sync"
+
+// Option configures a table created with NewWithOptions.
+type Option func(*options)
+
+type options struct {
+	threadSafe bool
+	stripes    int
+	maxColumns int
+	bounded    bool
+	buf        []byte
+}
+
+// WithThreadSafe makes the table safe for concurrent use, like NewTS.
+func WithThreadSafe() Option {
+	return func(o *options) {
+		o.threadSafe = true
+	}
+}
+
+// WithSharding makes the table safe for concurrent use with n striped locks,
+// like NewTSWithLocking with PerRowStriped(n).
+func WithSharding(n int) Option {
+	return func(o *options) {
+		o.threadSafe = true
+		o.stripes = PerRowStriped(n).stripes
+	}
+}
+
+// WithMaxColumns makes NewWithOptions return ErrTooManyColumns when the
+// amount of columns exceeds n, like NewBounded.
+func WithMaxColumns(n int) Option {
+	return func(o *options) {
+		o.maxColumns = n
+		o.bounded = true
+	}
+}
+
+// WithBuffer makes the table use buf as its data, like NewFromData.
+func WithBuffer(buf []byte) Option {
+	return func(o *options) {
+		o.buf = buf
+	}
+}
+
+// NewWithOptions creates a new Bitmaptable instance configured by the
+// provided options. Without options it is equivalent to New.
+func NewWithOptions(rows, columns int, opts ...Option) (Bitmaptable, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bounded {
+		if err := checkMaxColumns(columns, o.maxColumns); err != nil {
+			return nil, err
+		}
+	}
+
+	var b *bitmaptable
+	if o.buf != nil {
+		t, err := NewFromData(rows, columns, o.buf)
+		if err != nil {
+			return nil, err
+		}
+		b = t.(*bitmaptable)
+	} else {
+		b = newNTS(rows, columns)
+	}
+
+	switch {
+	case o.stripes > 0:
+		return newStriped(b, o.stripes), nil
+	case o.threadSafe:
+		return &ts{mu: new(sync.Mutex), b: b}, nil
+	}
+	return b, nil
+}
@@ -0,0 +1,46 @@
+package bitmaptable
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	b, err := NewWithOptions(10, 5)
+	if err != nil || IsThreadSafe(b) || b.Rows() != 10 || b.Columns() != 5 {
+		t.Fatal("wrong default table", err)
+	}
+
+	b, err = NewWithOptions(10, 5, WithThreadSafe(), WithMaxColumns(5))
+	if err != nil || !IsThreadSafe(b) {
+		t.Fatal("table must be thread-safe", err)
+	}
+	if _, ok := b.(*striped); ok {
+		t.Fatal("table mustn't be striped without sharding")
+	}
+
+	b, err = NewWithOptions(10, 5, WithSharding(4))
+	if err != nil || !IsThreadSafe(b) {
+		t.Fatal("sharded table must be thread-safe", err)
+	}
+	if s, ok := b.(*striped); !ok || len(s.stripes) != 4 {
+		t.Fatal("wrong amount of shards")
+	}
+
+	if _, err := NewWithOptions(10, 6, WithThreadSafe(), WithMaxColumns(5)); !errors.Is(err, ErrTooManyColumns) {
+		t.Fatal("too many columns must be returned", err)
+	}
+
+	buf := make([]byte, 7)
+	b, err = NewWithOptions(10, 5, WithBuffer(buf), WithSharding(2))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	b.Set(9, 4, true)
+	if buf[6] != 0x02 {
+		t.Fatal("table must use the provided buffer")
+	}
+	if _, err := NewWithOptions(10, 6, WithBuffer(buf)); !errors.Is(err, ErrIllegalData) {
+		t.Fatal("illegal data must be returned for a short buffer", err)
+	}
+}
@@ -0,0 +1,29 @@
+package bitmaptable
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// MaxImageDimension is the maximum amount of rows or columns of a table
+// written by WritePNG.
+const MaxImageDimension = 1 << 15
+
+// WritePNG implements Bitmaptable.WritePNG
+func (b *bitmaptable) WritePNG(w io.Writer) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if b.rows > MaxImageDimension || b.columns > MaxImageDimension {
+		return ErrImageSize
+	}
+	// A palette of two colors is encoded with a single bit per pixel.
+	img := image.NewPaletted(image.Rect(0, 0, b.columns, b.rows), color.Palette{color.White, color.Black})
+	b.eachSetBit(func(row, column int) bool {
+		img.Pix[row*img.Stride+column] = 1
+		return true
+	})
+	return png.Encode(w, img)
+}
@@ -0,0 +1,41 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	for _, b := range []Bitmaptable{New(9, 13), NewTS(9, 13), NewAligned(9, 13)} {
+		b.Set(0, 0, true)
+		b.Set(4, 7, true)
+		b.Set(8, 12, true)
+
+		buf := new(bytes.Buffer)
+		if err := b.WritePNG(buf); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		img, err := png.Decode(buf)
+		if err != nil {
+			t.Fatal("output must be a valid PNG", err)
+		}
+		if bounds := img.Bounds(); bounds.Dx() != 13 || bounds.Dy() != 9 {
+			t.Fatal("wrong image size", bounds)
+		}
+		for row := 0; row < 9; row++ {
+			for column := 0; column < 13; column++ {
+				v, _ := b.Get(row, column)
+				gray := color.GrayModel.Convert(img.At(column, row)).(color.Gray)
+				if (gray.Y == 0) != v {
+					t.Fatal("wrong pixel at", row, column)
+				}
+			}
+		}
+	}
+
+	if err := New(1, MaxImageDimension+1).WritePNG(new(bytes.Buffer)); err != ErrImageSize {
+		t.Fatal("image size error must be returned", err)
+	}
+}
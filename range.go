@@ -0,0 +1,13 @@
+package bitmaptable
+
+// RangeAll implements Bitmaptable.RangeAll
+func (b *bitmaptable) RangeAll(fn func(row, column int, value bool) bool) {
+	for row := 0; row < b.rows; row++ {
+		offset := row * b.stride
+		for column := 0; column < b.columns; column++ {
+			if !fn(row, column, b.bitmap.Get(offset+column)) {
+				return
+			}
+		}
+	}
+}
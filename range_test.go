@@ -0,0 +1,37 @@
+package bitmaptable
+
+import "testing"
+
+func TestRangeAll(t *testing.T) {
+	for _, b := range []Bitmaptable{New(6, 11), NewTS(6, 11), NewAligned(6, 11)} {
+		b.Set(0, 3, true)
+		b.Set(5, 10, true)
+
+		visits, set := 0, 0
+		b.RangeAll(func(row, column int, value bool) bool {
+			if row != visits/11 || column != visits%11 {
+				t.Fatal("cells must be visited in row-major order")
+			}
+			if value != ((row == 0 && column == 3) || (row == 5 && column == 10)) {
+				t.Fatal("wrong value at", row, column)
+			}
+			if value {
+				set++
+			}
+			visits++
+			return true
+		})
+		if visits != 6*11 || set != 2 {
+			t.Fatal("every cell must be visited", visits, set)
+		}
+
+		visits = 0
+		b.RangeAll(func(row, column int, value bool) bool {
+			visits++
+			return visits < 20
+		})
+		if visits != 20 {
+			t.Fatal("iteration must stop when fn returns false", visits)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bitmaptable
+
+// CopyRegion copies the block of nRows by nCols cells starting at srcRow and
+// srcCol in src to dstRow and dstCol in dst. The regions may overlap when src
+// and dst are the same table.
+func CopyRegion(dst Bitmaptable, dstRow, dstCol int, src Bitmaptable, srcRow, srcCol, nRows, nCols int) error {
+	if nRows < 0 || nCols < 0 ||
+		!inRegion(src, srcRow, srcCol, nRows, nCols) ||
+		!inRegion(dst, dstRow, dstCol, nRows, nCols) {
+		return ErrIllegalIndex
+	}
+
+	values := make([]bool, nRows*nCols)
+	for r := 0; r < nRows; r++ {
+		for c := 0; c < nCols; c++ {
+			v, err := src.Get(srcRow+r, srcCol+c)
+			if err != nil {
+				return err
+			}
+			values[r*nCols+c] = v
+		}
+	}
+	for r := 0; r < nRows; r++ {
+		for c := 0; c < nCols; c++ {
+			if err := dst.Set(dstRow+r, dstCol+c, values[r*nCols+c]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inRegion returns whether the region of nRows by nCols cells at row and
+// column lies within the table. The sizes are compared against the space left
+// after row and column, so that huge sizes can't overflow.
+func inRegion(b Bitmaptable, row, column, nRows, nCols int) bool {
+	return row >= 0 && column >= 0 && nRows <= b.Rows()-row && nCols <= b.Columns()-column
+}
+
+// CountRegion implements Bitmaptable.CountRegion
+//
+// Every row of the region is counted separately, which costs O(nRows*nCols/8)
+// for wide regions but O(nRows) for narrow ones.
+func (b *bitmaptable) CountRegion(startRow, startCol, nRows, nCols int) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if nRows < 0 || nCols < 0 || !inRegion(b, startRow, startCol, nRows, nCols) {
+		return 0, ErrIllegalIndex
+	}
+	count := 0
+	for row := startRow; row < startRow+nRows; row++ {
+		offset := row*b.stride + startCol
+		count += b.countRange(offset, offset+nCols)
+	}
+	return count, nil
+}
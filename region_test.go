@@ -0,0 +1,76 @@
+package bitmaptable
+
+import "testing"
+
+func TestCopyRegion(t *testing.T) {
+	src := New(6, 6)
+	for i := 0; i < 36; i += 2 {
+		src.Set(i/6, i%6, true)
+	}
+	dst := NewTS(8, 7)
+	dst.Set(0, 0, true)
+	dst.Set(7, 6, true)
+
+	if err := CopyRegion(dst, 4, 3, src, 1, 2, 3, 3); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for row := 0; row < 8; row++ {
+		for column := 0; column < 7; column++ {
+			var expected bool
+			if row >= 4 && row < 7 && column >= 3 && column < 6 {
+				expected, _ = src.Get(row-3, column-1)
+			} else {
+				expected = (row == 0 && column == 0) || (row == 7 && column == 6)
+			}
+			if v, _ := dst.Get(row, column); v != expected {
+				t.Fatal("wrong value at", row, column)
+			}
+		}
+	}
+
+	if err := CopyRegion(dst, 6, 0, src, 0, 0, 3, 3); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if err := CopyRegion(dst, 0, 0, src, 4, 0, 3, 3); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if err := CopyRegion(dst, 0, -1, src, 0, 0, 1, 1); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if err := CopyRegion(dst, 1, 0, src, 1, 0, int(maxInt), 1); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned for huge regions")
+	}
+}
+
+func TestCountRegion(t *testing.T) {
+	for _, b := range []Bitmaptable{New(20, 13), NewTS(20, 13), NewAligned(20, 13)} {
+		for i := 0; i < 260; i += 3 {
+			b.Set(i/13, i%13, true)
+		}
+		for _, r := range [][4]int{{0, 0, 20, 13}, {0, 0, 8, 8}, {3, 5, 7, 6}, {19, 12, 1, 1}, {4, 4, 0, 3}, {4, 4, 3, 0}} {
+			expected := 0
+			for row := r[0]; row < r[0]+r[2]; row++ {
+				for column := r[1]; column < r[1]+r[3]; column++ {
+					if v, _ := b.Get(row, column); v {
+						expected++
+					}
+				}
+			}
+			if count, err := b.CountRegion(r[0], r[1], r[2], r[3]); err != nil || count != expected {
+				t.Fatal("wrong count for region", r, count, expected)
+			}
+		}
+		if _, err := b.CountRegion(15, 0, 6, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.CountRegion(0, -1, 1, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.CountRegion(1, 0, int(maxInt), 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned for huge regions")
+		}
+		if _, err := b.CountRegion(0, 1, 1, int(maxInt)); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned for huge regions")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package bitmaptable
+
+// RingTable is a fixed capacity bitmap table used as a ring buffer of rows:
+// once full, every pushed row overwrites the oldest one.
+type RingTable struct {
+	table *bitmaptable
+	next  int // Slot the next row is pushed into.
+	count int // Amount of slots in use.
+}
+
+// NewRing creates a new RingTable of capacity rows of the provided amount of
+// columns.
+func NewRing(capacity, columns int) *RingTable {
+	return &RingTable{table: newNTS(capacity, columns)}
+}
+
+// Capacity returns the amount of slots of the ring.
+func (r *RingTable) Capacity() int {
+	return r.table.rows
+}
+
+// Len returns the amount of slots in use.
+func (r *RingTable) Len() int {
+	return r.count
+}
+
+// Push writes values into the slot of the oldest row and returns that slot.
+// Missing values are false and values beyond the amount of columns are
+// ignored.
+func (r *RingTable) Push(values []bool) (slot int) {
+	if r.table.rows == 0 {
+		return -1
+	}
+	slot = r.next
+	offset := slot * r.table.stride
+	for column := 0; column < r.table.columns; column++ {
+		r.table.bitmap.Set(offset+column, column < len(values) && values[column])
+	}
+	r.next = (r.next + 1) % r.table.rows
+	if r.count < r.table.rows {
+		r.count++
+	}
+	return slot
+}
+
+// Get gets the value of the provided column in the provided slot.
+func (r *RingTable) Get(slot, column int) (bool, error) {
+	return r.table.Get(slot, column)
+}
@@ -0,0 +1,44 @@
+package bitmaptable
+
+import "testing"
+
+func TestRingTable(t *testing.T) {
+	r := NewRing(3, 2)
+	if r.Capacity() != 3 || r.Len() != 0 {
+		t.Fatal("wrong configuration")
+	}
+
+	pushes := [][]bool{
+		{true, false},
+		{false, true},
+		{true, true},
+		{false, false},
+		{false, true, true},
+	}
+	expectedSlots := []int{0, 1, 2, 0, 1}
+	for i, values := range pushes {
+		if slot := r.Push(values); slot != expectedSlots[i] {
+			t.Fatal("wrong slot", slot)
+		}
+	}
+	if r.Len() != 3 {
+		t.Fatal("wrong length")
+	}
+
+	expected := [][]bool{{false, false}, {false, true}, {true, true}}
+	for slot, values := range expected {
+		for column, e := range values {
+			if v, err := r.Get(slot, column); err != nil || v != e {
+				t.Fatal("wrong value at", slot, column)
+			}
+		}
+	}
+
+	r.Push([]bool{true})
+	if v, _ := r.Get(2, 1); v {
+		t.Fatal("missing values must be false")
+	}
+	if _, err := r.Get(3, 0); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+}
@@ -0,0 +1,448 @@
+package bitmaptable
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+
+	"github.com/boljen/go-bitmap"
+)
+
+// RangeRowsParallel implements Bitmaptable.RangeRowsParallel
+func (b *bitmaptable) RangeRowsParallel(workers int, fn func(row int, values []bool)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > b.rows {
+		workers = b.rows
+	}
+	if workers == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	per := (b.rows + workers - 1) / workers
+	for start := 0; start < b.rows; start += per {
+		end := start + per
+		if end > b.rows {
+			end = b.rows
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			values := make([]bool, b.columns)
+			for row := start; row < end; row++ {
+				b.row(row, values)
+				fn(row, values)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// RowMajority implements Bitmaptable.RowMajority
+func (b *bitmaptable) RowMajority(row int) (bool, error) {
+	if err := b.check(row, 0); err != nil {
+		return false, err
+	}
+	return b.rowPopcount(row)*2 > b.columns, nil
+}
+
+// FlipRow implements Bitmaptable.FlipRow
+func (b *bitmaptable) FlipRow(row int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if err := b.checkRow(row); err != nil {
+		return err
+	}
+	b.flipRange(row*b.stride, row*b.stride+b.columns)
+	return nil
+}
+
+// RowsWithinHamming implements Bitmaptable.RowsWithinHamming
+func (b *bitmaptable) RowsWithinHamming(pattern []bool, maxDist int) ([]int, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if len(pattern) != b.columns {
+		return nil, ErrPattern
+	}
+	rows := []int{}
+	for row := 0; row < b.rows; row++ {
+		dist, offset := 0, row*b.stride
+		for column, v := range pattern {
+			if b.bitmap.Get(offset+column) != v {
+				if dist++; dist > maxDist {
+					break
+				}
+			}
+		}
+		if dist <= maxDist {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// SortRows implements Bitmaptable.SortRows
+func (b *bitmaptable) SortRows(less func(a, b []bool) bool) {
+	if b.writable() != nil {
+		return
+	}
+	order := make([]int, b.rows)
+	for i := range order {
+		order[i] = i
+	}
+	x, y := make([]bool, b.columns), make([]bool, b.columns)
+	sort.SliceStable(order, func(i, j int) bool {
+		b.row(order[i], x)
+		b.row(order[j], y)
+		return less(x, y)
+	})
+	b.permute(order)
+}
+
+// permute reorders the rows so that row i holds what was row order[i].
+func (b *bitmaptable) permute(order []int) {
+	old := b.bitmap.Data(true)
+	for dst, src := range order {
+		b.copyRow(dst, old, src)
+	}
+}
+
+// BroadcastRowOr implements Bitmaptable.BroadcastRowOr
+func (b *bitmaptable) BroadcastRowOr(pattern []bool) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if len(pattern) != b.columns {
+		return ErrPattern
+	}
+
+	// Rows that start on a byte boundary can be ORed a byte at a time.
+	if b.stride%8 == 0 {
+		p := make([]byte, b.stride/8)
+		for column, v := range pattern {
+			if v {
+				p[column/8] |= 1 << uint(column%8)
+			}
+		}
+		for i := 0; i < b.rows*b.stride/8; i++ {
+			b.bitmap[i] |= p[i%len(p)]
+		}
+		return nil
+	}
+
+	for row := 0; row < b.rows; row++ {
+		offset := row * b.stride
+		for column, v := range pattern {
+			if v {
+				b.bitmap.Set(offset+column, true)
+			}
+		}
+	}
+	return nil
+}
+
+// rowPopcount returns the amount of set columns in the provided row.
+func (b *bitmaptable) rowPopcount(row int) int {
+	return b.countRange(row*b.stride, row*b.stride+b.columns)
+}
+
+// row reads the columns of the provided row into values.
+func (b *bitmaptable) row(row int, values []bool) {
+	offset := row * b.stride
+	for column := range values {
+		values[column] = b.bitmap.Get(offset + column)
+	}
+}
+
+// OrRowsInto implements Bitmaptable.OrRowsInto
+func (b *bitmaptable) OrRowsInto(dst Bitmaptable, rowMap []int) error {
+	coords, err := b.mapSetBits(dst.Rows(), dst.Columns(), rowMap)
+	if err != nil {
+		return err
+	}
+	return setCoords(dst, coords)
+}
+
+// mapSetBits returns the coordinates of the set cells with their rows mapped
+// through rowMap into a table of the provided dimensions.
+func (b *bitmaptable) mapSetBits(rows, columns int, rowMap []int) ([]Coord, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if columns != b.columns || len(rowMap) != b.rows {
+		return nil, ErrDimensions
+	}
+	for _, row := range rowMap {
+		if row < 0 || row >= rows {
+			return nil, ErrIllegalIndex
+		}
+	}
+	coords := []Coord{}
+	b.eachSetBit(func(row, column int) bool {
+		coords = append(coords, Coord{Row: rowMap[row], Column: column})
+		return true
+	})
+	return coords, nil
+}
+
+// setCoords sets the cells at the provided coordinates of b.
+func setCoords(b Bitmaptable, coords []Coord) error {
+	for _, c := range coords {
+		if err := b.Set(c.Row, c.Column, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DownsampleRowsAnd implements Bitmaptable.DownsampleRowsAnd
+func (b *bitmaptable) DownsampleRowsAnd(k int) (Bitmaptable, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if k < 1 {
+		return nil, ErrIllegalSize
+	}
+	groups := b.rows / k
+	if b.rows%k != 0 {
+		groups++
+	}
+	r := newNTS(groups, b.columns)
+	for group := 0; group < r.rows; group++ {
+		start, end := group*k, (group+1)*k
+		if end > b.rows {
+			end = b.rows
+		}
+		for column := 0; column < b.columns; column++ {
+			all := true
+			for row := start; row < end && all; row++ {
+				all = b.bitmap.Get(row*b.stride + column)
+			}
+			if all {
+				r.bitmap.Set(group*r.stride+column, true)
+			}
+		}
+	}
+	return r, nil
+}
+
+// MostCommonRow implements Bitmaptable.MostCommonRow
+func (b *bitmaptable) MostCommonRow() ([]bool, int) {
+	// Rows are packed into strings so that they can be counted in a map,
+	// along with the first row holding them.
+	type occurrence struct{ first, count int }
+	seen := make(map[string]occurrence)
+	key := make([]byte, (b.columns+7)/8)
+	best, bestCount := -1, 0
+	for row := 0; row < b.rows; row++ {
+		b.packRow(row, key)
+		o, ok := seen[string(key)]
+		if !ok {
+			o.first = row
+		}
+		o.count++
+		seen[string(key)] = o
+		if o.count > bestCount || (o.count == bestCount && o.first < best) {
+			best, bestCount = o.first, o.count
+		}
+	}
+	if best < 0 {
+		return nil, 0
+	}
+	pattern := make([]bool, b.columns)
+	b.row(best, pattern)
+	return pattern, bestCount
+}
+
+// ClearRowsBelow implements Bitmaptable.ClearRowsBelow
+func (b *bitmaptable) ClearRowsBelow(row int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if row < 0 || row > b.rows {
+		return ErrIllegalIndex
+	}
+	// Whole bytes are cleared up to the byte holding the boundary, of which
+	// only the bits before the boundary are cleared.
+	n := row * b.stride
+	for i := 0; i < n/8; i++ {
+		b.bitmap[i] = 0
+	}
+	if n%8 != 0 {
+		b.bitmap[n/8] &^= 1<<uint(n%8) - 1
+	}
+	return nil
+}
+
+// AppendTable implements Bitmaptable.AppendTable
+func (b *bitmaptable) AppendTable(other Bitmaptable) error {
+	return b.appendData(other.Rows(), other.Columns(), other.Stride(), other.SafeData())
+}
+
+func (b *bitmaptable) appendData(rows, columns, stride int, data []byte) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if columns != b.columns {
+		return ErrDimensions
+	}
+	start := b.rows * b.stride
+	b.grow(b.rows + rows)
+	if stride == b.stride && start%8 == 0 {
+		// The new rows start at a byte boundary, so the data can be copied
+		// as is.
+		copy(b.bitmap[start/8:], data[:(rows*stride+7)/8])
+		b.normalize()
+		return nil
+	}
+	from := bitmap.Bitmap(data)
+	for row := 0; row < rows; row++ {
+		d, s := start+row*b.stride, row*stride
+		for column := 0; column < columns; column++ {
+			if from.Get(s + column) {
+				b.bitmap.Set(d+column, true)
+			}
+		}
+	}
+	return nil
+}
+
+// BitReversedPermutation implements Bitmaptable.BitReversedPermutation
+func (b *bitmaptable) BitReversedPermutation() []int {
+	n := bits.Len(uint(b.rows - 1))
+	if b.rows < 2 {
+		n = 0
+	}
+	perm := make([]int, 0, b.rows)
+	for i := 0; i < 1<<uint(n); i++ {
+		r := int(bits.Reverse(uint(i)) >> uint(bits.UintSize-n))
+		if r < b.rows {
+			perm = append(perm, r)
+		}
+	}
+	return perm
+}
+
+// PermuteRows implements Bitmaptable.PermuteRows
+func (b *bitmaptable) PermuteRows(perm []int) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if len(perm) != b.rows {
+		return ErrPermutation
+	}
+	seen := make([]bool, b.rows)
+	for _, row := range perm {
+		if row < 0 || row >= b.rows || seen[row] {
+			return ErrPermutation
+		}
+		seen[row] = true
+	}
+	b.permute(perm)
+	return nil
+}
+
+// NonEmptyRowCount implements Bitmaptable.NonEmptyRowCount
+func (b *bitmaptable) NonEmptyRowCount() int {
+	n := 0
+	for offset := 0; offset < b.rows*b.stride; offset += b.stride {
+		if b.anyRange(offset, offset+b.columns) {
+			n++
+		}
+	}
+	return n
+}
+
+// packRow packs the columns of the provided row into key, which must hold
+// (Columns()+7)/8 bytes.
+func (b *bitmaptable) packRow(row int, key []byte) {
+	for i := range key {
+		key[i] = 0
+	}
+	offset := row * b.stride
+	for column := 0; column < b.columns; column++ {
+		if b.bitmap.Get(offset + column) {
+			key[column/8] |= 1 << uint(column%8)
+		}
+	}
+}
+
+// RowsMatchingAny implements Bitmaptable.RowsMatchingAny
+func (b *bitmaptable) RowsMatchingAny(patterns [][]bool) ([]int, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	set := make(map[string]bool, len(patterns))
+	for _, pattern := range patterns {
+		if len(pattern) != b.columns {
+			return nil, ErrPattern
+		}
+		key := make([]byte, (b.columns+7)/8)
+		for column, v := range pattern {
+			if v {
+				key[column/8] |= 1 << uint(column%8)
+			}
+		}
+		set[string(key)] = true
+	}
+	rows := []int{}
+	key := make([]byte, (b.columns+7)/8)
+	for row := 0; row < b.rows; row++ {
+		b.packRow(row, key)
+		if set[string(key)] {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// TrimTrailing implements Bitmaptable.TrimTrailing
+func (b *bitmaptable) TrimTrailing() int {
+	if b.writable() != nil {
+		return b.rows
+	}
+	rows := b.rows
+	for rows > 0 && !b.anyRange((rows-1)*b.stride, (rows-1)*b.stride+b.columns) {
+		rows--
+	}
+	if rows == b.rows {
+		return rows
+	}
+	data := newAligned(rows * b.stride)
+	copy(data, b.bitmap)
+	b.bitmap = data
+	b.surplus = 0
+	b.rows = rows
+	b.normalize()
+	return rows
+}
+
+// RowParityColumn implements Bitmaptable.RowParityColumn
+func (b *bitmaptable) RowParityColumn() Bitmaptable {
+	r := newNTS(b.rows, 1)
+	for row := 0; row < b.rows; row++ {
+		if b.rowPopcount(row)%2 == 1 {
+			r.bitmap.Set(row, true)
+		}
+	}
+	return r
+}
+
+// FindRow implements Bitmaptable.FindRow
+func (b *bitmaptable) FindRow(pred func(values []bool) bool) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	values := make([]bool, b.columns)
+	for row := 0; row < b.rows; row++ {
+		b.row(row, values)
+		if pred(values) {
+			return row, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,577 @@
+package bitmaptable
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRangeRowsParallel(t *testing.T) {
+	for _, b := range []Bitmaptable{New(101, 7), NewTS(101, 7)} {
+		for i := 0; i < 101*7; i += 3 {
+			b.Set(i/7, i%7, true)
+		}
+
+		expected := make([]int64, 101)
+		for row := 0; row < 101; row++ {
+			for column := 0; column < 7; column++ {
+				if v, _ := b.Get(row, column); v {
+					expected[row]++
+				}
+			}
+		}
+
+		for _, workers := range []int{0, 1, 4, 200} {
+			counts := make([]int64, 101)
+			b.RangeRowsParallel(workers, func(row int, values []bool) {
+				for _, v := range values {
+					if v {
+						atomic.AddInt64(&counts[row], 1)
+					}
+				}
+			})
+			for row := range counts {
+				if counts[row] != expected[row] {
+					t.Fatal("wrong popcount for row", row, "with workers", workers)
+				}
+			}
+		}
+	}
+}
+
+func TestRowMajority(t *testing.T) {
+	for _, b := range []Bitmaptable{New(3, 4), NewTS(3, 4)} {
+		b.Set(0, 0, true)
+		b.Set(1, 1, true)
+		b.Set(1, 3, true)
+		b.Set(2, 0, true)
+		b.Set(2, 2, true)
+		b.Set(2, 3, true)
+		for row, expected := range []bool{false, false, true} {
+			if v, err := b.RowMajority(row); err != nil || v != expected {
+				t.Fatal("wrong majority for row", row)
+			}
+		}
+		if _, err := b.RowMajority(3); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+
+	b := New(1, 5)
+	b.Set(0, 0, true)
+	b.Set(0, 1, true)
+	if v, _ := b.RowMajority(0); v {
+		t.Fatal("two of five columns isn't a majority")
+	}
+	b.Set(0, 4, true)
+	if v, _ := b.RowMajority(0); !v {
+		t.Fatal("three of five columns is a majority")
+	}
+}
+
+func TestFlipRow(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), New(3, 21)} {
+		columns := b.Columns()
+		for i := 0; i < b.Rows()*columns; i += 2 {
+			b.Set(i/columns, i%columns, true)
+		}
+		before := b.Data(true)
+		if err := b.FlipRow(1); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < b.Rows(); row++ {
+			for c := 0; c < columns; c++ {
+				i := row*columns + c
+				old := before[i/8]&(1<<uint(i%8)) != 0
+				if v, _ := b.Get(row, c); v != (old != (row == 1)) {
+					t.Fatal("wrong value at", row, c)
+				}
+			}
+		}
+		if err := b.FlipRow(b.Rows()); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}
+
+func TestRowsWithinHamming(t *testing.T) {
+	for _, b := range []Bitmaptable{New(4, 3), NewTS(4, 3)} {
+		// 000, 101, 111, 100
+		b.Set(1, 0, true)
+		b.Set(1, 2, true)
+		b.Set(2, 0, true)
+		b.Set(2, 1, true)
+		b.Set(2, 2, true)
+		b.Set(3, 0, true)
+
+		pattern := []bool{true, false, true}
+		if rows, err := b.RowsWithinHamming(pattern, 0); err != nil || !reflect.DeepEqual(rows, []int{1}) {
+			t.Fatal("wrong exact matches", rows)
+		}
+		if rows, _ := b.RowsWithinHamming(pattern, 1); !reflect.DeepEqual(rows, []int{1, 2, 3}) {
+			t.Fatal("wrong matches", rows)
+		}
+		if rows, _ := b.RowsWithinHamming(pattern, 3); !reflect.DeepEqual(rows, []int{0, 1, 2, 3}) {
+			t.Fatal("wrong matches", rows)
+		}
+		if _, err := b.RowsWithinHamming([]bool{true}, 1); err != ErrPattern {
+			t.Fatal("pattern error must be returned")
+		}
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	popcount := func(values []bool) int {
+		n := 0
+		for _, v := range values {
+			if v {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, b := range []Bitmaptable{New(5, 3), NewTS(5, 3)} {
+		// 110, 000, 111, 001, 100
+		for _, c := range []Coord{{0, 0}, {0, 1}, {2, 0}, {2, 1}, {2, 2}, {3, 2}, {4, 0}} {
+			b.Set(c.Row, c.Column, true)
+		}
+
+		b.SortRows(func(x, y []bool) bool { return popcount(x) < popcount(y) })
+		expected := [][]bool{
+			{false, false, false},
+			{false, false, true},
+			{true, false, false},
+			{true, true, false},
+			{true, true, true},
+		}
+		values := make([]bool, 3)
+		for row := range expected {
+			for column := range values {
+				values[column], _ = b.Get(row, column)
+			}
+			if !reflect.DeepEqual(values, expected[row]) {
+				t.Fatal("wrong row after popcount sort", row, values)
+			}
+		}
+
+		b.SortRows(func(x, y []bool) bool { return x[0] && !y[0] })
+		expected = [][]bool{
+			{true, false, false},
+			{true, true, false},
+			{true, true, true},
+			{false, false, false},
+			{false, false, true},
+		}
+		for row := range expected {
+			for column := range values {
+				values[column], _ = b.Get(row, column)
+			}
+			if !reflect.DeepEqual(values, expected[row]) {
+				t.Fatal("wrong row after column sort", row, values)
+			}
+		}
+	}
+}
+
+func TestBroadcastRowOr(t *testing.T) {
+	for _, b := range []Bitmaptable{New(7, 5), NewTS(7, 5), NewAligned(7, 5), New(7, 8)} {
+		columns := b.Columns()
+		b.Set(0, 1, true)
+		b.Set(3, 4, true)
+		pattern := make([]bool, columns)
+		pattern[0], pattern[2] = true, true
+		if err := b.BroadcastRowOr(pattern); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 7; row++ {
+			for column := 0; column < columns; column++ {
+				expected := column == 0 || column == 2 || (row == 0 && column == 1) || (row == 3 && column == 4)
+				if v, _ := b.Get(row, column); v != expected {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		if b.PaddingSet() {
+			t.Fatal("padding mustn't be set")
+		}
+		if err := b.BroadcastRowOr([]bool{true}); err != ErrPattern {
+			t.Fatal("pattern error must be returned")
+		}
+	}
+}
+
+func TestOrRowsInto(t *testing.T) {
+	for _, src := range []Bitmaptable{New(3, 4), NewTS(3, 4), NewAligned(3, 4)} {
+		src.Set(0, 0, true)
+		src.Set(1, 1, true)
+		src.Set(1, 2, true)
+		src.Set(2, 3, true)
+
+		dst := NewTS(10, 4)
+		dst.Set(7, 0, true)
+		dst.Set(2, 3, true)
+		if err := src.OrRowsInto(dst, []int{2, 7, 9}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		expected := map[Coord]bool{{2, 0}: true, {2, 3}: true, {7, 0}: true, {7, 1}: true, {7, 2}: true, {9, 3}: true}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 4; column++ {
+				if v, _ := dst.Get(row, column); v != expected[Coord{row, column}] {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+
+		if err := src.OrRowsInto(New(10, 5), []int{0, 1, 2}); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+		if err := src.OrRowsInto(dst, []int{0, 1}); err != ErrDimensions {
+			t.Fatal("dimension error must be returned")
+		}
+		if err := src.OrRowsInto(dst, []int{0, 1, 10}); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if err := src.OrRowsInto(src, []int{1, 2, 0}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func TestDownsampleRowsAnd(t *testing.T) {
+	for _, b := range []Bitmaptable{New(8, 3), NewTS(8, 3), NewAligned(8, 3)} {
+		// Column 0 is set everywhere, column 1 in rows 0-2 and 6-7,
+		// column 2 in rows 3 and 7.
+		for row := 0; row < 8; row++ {
+			b.Set(row, 0, true)
+		}
+		for _, row := range []int{0, 1, 2, 6, 7} {
+			b.Set(row, 1, true)
+		}
+		b.Set(3, 2, true)
+		b.Set(7, 2, true)
+
+		for k, expected := range map[int][][]bool{
+			3: {{true, true, false}, {true, false, false}, {true, true, false}},
+			4: {{true, false, false}, {true, false, false}},
+			1: nil,
+		} {
+			r, err := b.DownsampleRowsAnd(k)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if k == 1 {
+				if !Equal(r, b) {
+					t.Fatal("k=1 must return an equal table")
+				}
+				continue
+			}
+			if r.Rows() != len(expected) || r.Columns() != 3 {
+				t.Fatal("wrong dimensions")
+			}
+			for row, values := range expected {
+				for column, e := range values {
+					if v, _ := r.Get(row, column); v != e {
+						t.Fatal("wrong value at", row, column, "for k", k)
+					}
+				}
+			}
+		}
+		if _, err := b.DownsampleRowsAnd(0); err != ErrIllegalSize {
+			t.Fatal("illegal size must be returned")
+		}
+		if r, err := b.DownsampleRowsAnd(int(maxInt)); err != nil || r.Rows() != 1 {
+			t.Fatal("a huge k must return a single row", err)
+		}
+	}
+}
+
+func TestMostCommonRow(t *testing.T) {
+	for _, b := range []Bitmaptable{New(12, 10), NewTS(12, 10), NewAligned(12, 10)} {
+		if pattern, count := b.MostCommonRow(); !reflect.DeepEqual(pattern, make([]bool, 10)) || count != 12 {
+			t.Fatal("wrong most common row", pattern, count)
+		}
+		for row := 0; row < 12; row++ {
+			if row%4 != 0 {
+				b.Set(row, 1, true)
+				b.Set(row, 9, true)
+			} else {
+				b.Set(row, row/4, true)
+			}
+		}
+		want := []bool{false, true, false, false, false, false, false, false, false, true}
+		if pattern, count := b.MostCommonRow(); !reflect.DeepEqual(pattern, want) || count != 9 {
+			t.Fatal("wrong most common row", pattern, count)
+		}
+	}
+
+	b := New(4, 3)
+	b.Set(1, 0, true)
+	b.Set(2, 0, true)
+	if pattern, count := b.MostCommonRow(); !reflect.DeepEqual(pattern, []bool{false, false, false}) || count != 2 {
+		t.Fatal("ties must favor the pattern that occurs first", pattern, count)
+	}
+	if pattern, count := New(0, 3).MostCommonRow(); pattern != nil || count != 0 {
+		t.Fatal("a table without rows must have no most common row")
+	}
+}
+
+func TestClearRowsBelow(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 3), NewTS(10, 3), NewAligned(10, 3)} {
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 3; column++ {
+				b.Set(row, column, true)
+			}
+		}
+		// The boundary at row 5 falls in the middle of a byte without
+		// alignment.
+		if err := b.ClearRowsBelow(5); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for row := 0; row < 10; row++ {
+			for column := 0; column < 3; column++ {
+				if v, _ := b.Get(row, column); v != (row >= 5) {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+		if err := b.ClearRowsBelow(0); err != nil || b.Count() != 15 {
+			t.Fatal("clearing below row 0 mustn't change anything", err)
+		}
+		if err := b.ClearRowsBelow(11); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if err := b.ClearRowsBelow(-1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if err := b.ClearRowsBelow(10); err != nil || b.Count() != 0 {
+			t.Fatal("clearing below Rows() must clear the table", err)
+		}
+	}
+}
+
+func TestAppendTable(t *testing.T) {
+	for _, newFn := range []func(int, int) Bitmaptable{New, NewTS, NewAligned} {
+		for _, rows := range []int{8, 5} {
+			a, b := newFn(rows, 3), New(4, 3)
+			for i := 0; i < rows*3; i += 2 {
+				a.Set(i/3, i%3, true)
+			}
+			for i := 0; i < 12; i += 5 {
+				b.Set(i/3, i%3, true)
+			}
+			if err := a.AppendTable(b); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if a.Rows() != rows+4 {
+				t.Fatal("wrong amount of rows", a.Rows())
+			}
+			for row := 0; row < rows+4; row++ {
+				for column := 0; column < 3; column++ {
+					want := (row*3+column)%2 == 0
+					if row >= rows {
+						want, _ = b.Get(row-rows, column)
+					}
+					if v, _ := a.Get(row, column); v != want {
+						t.Fatal("wrong value at", row, column)
+					}
+				}
+			}
+			if a.PaddingSet() {
+				t.Fatal("padding must be cleared")
+			}
+
+			before := a.Count()
+			if err := a.AppendTable(a); err != nil || a.Rows() != 2*(rows+4) || a.Count() != 2*before {
+				t.Fatal("a table must be appendable to itself", err)
+			}
+			if err := a.AppendTable(New(1, 4)); err != ErrDimensions {
+				t.Fatal("dimension error must be returned")
+			}
+		}
+	}
+}
+
+func TestBitReversedPermutation(t *testing.T) {
+	for _, b := range []Bitmaptable{New(8, 2), NewTS(8, 2), NewAligned(8, 2)} {
+		if p := b.BitReversedPermutation(); !reflect.DeepEqual(p, []int{0, 4, 2, 6, 1, 5, 3, 7}) {
+			t.Fatal("wrong permutation", p)
+		}
+	}
+	if p := New(6, 2).BitReversedPermutation(); !reflect.DeepEqual(p, []int{0, 4, 2, 1, 5, 3}) {
+		t.Fatal("wrong permutation", p)
+	}
+	if p := New(1, 2).BitReversedPermutation(); !reflect.DeepEqual(p, []int{0}) {
+		t.Fatal("wrong permutation", p)
+	}
+	if p := New(0, 2).BitReversedPermutation(); len(p) != 0 {
+		t.Fatal("wrong permutation", p)
+	}
+}
+
+func TestPermuteRows(t *testing.T) {
+	for _, b := range []Bitmaptable{New(5, 3), NewTS(5, 3), NewAligned(5, 3)} {
+		for row := 0; row < 5; row++ {
+			b.Set(row, row%3, true)
+		}
+		b.Set(4, 2, true)
+		if err := b.PermuteRows([]int{4, 0, 3, 1, 2}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		want := [][]bool{
+			{false, true, true},
+			{true, false, false},
+			{true, false, false},
+			{false, true, false},
+			{false, false, true},
+		}
+		for row, values := range want {
+			for column, v := range values {
+				if got, _ := b.Get(row, column); got != v {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+
+		for _, perm := range [][]int{{0, 1, 2, 3}, {0, 1, 2, 3, 3}, {0, 1, 2, 3, 5}, {-1, 1, 2, 3, 4}} {
+			if err := b.PermuteRows(perm); err != ErrPermutation {
+				t.Fatal("permutation error must be returned", perm)
+			}
+		}
+		if b.Count() != 6 {
+			t.Fatal("invalid permutations mustn't change the table")
+		}
+	}
+}
+
+func TestNonEmptyRowCount(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 20), NewTS(10, 20), NewAligned(10, 20), New(10, 3)} {
+		if n := b.NonEmptyRowCount(); n != 0 {
+			t.Fatal("an empty table mustn't have non-empty rows", n)
+		}
+		last := b.Columns() - 1
+		b.Set(0, 0, true)
+		b.Set(3, last, true)
+		b.Set(4, 1, true)
+		b.Set(4, last, true)
+		b.Set(9, last/2, true)
+		if n := b.NonEmptyRowCount(); n != 4 {
+			t.Fatal("wrong amount of non-empty rows", n)
+		}
+	}
+}
+
+func TestRowsMatchingAny(t *testing.T) {
+	for _, b := range []Bitmaptable{New(6, 3), NewTS(6, 3), NewAligned(6, 3)} {
+		for row := 0; row < 6; row++ {
+			b.Set(row, 0, row%2 == 0)
+			b.Set(row, 2, row%3 == 0)
+		}
+		// Rows hold 101, 000, 100, 001, 100, 000.
+		rows, err := b.RowsMatchingAny([][]bool{{true, false, false}, {false, false, true}})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if !reflect.DeepEqual(rows, []int{2, 3, 4}) {
+			t.Fatal("wrong rows", rows)
+		}
+		if rows, _ := b.RowsMatchingAny(nil); len(rows) != 0 {
+			t.Fatal("no rows must match without patterns", rows)
+		}
+		if _, err := b.RowsMatchingAny([][]bool{{true, false, false}, {true}}); err != ErrPattern {
+			t.Fatal("pattern error must be returned")
+		}
+	}
+}
+
+func TestTrimTrailing(t *testing.T) {
+	for _, b := range []Bitmaptable{New(40, 1), NewTS(40, 1), NewAligned(40, 1), New(40, 3)} {
+		for row := 0; row < 20; row += 3 {
+			b.Set(row, 0, true)
+		}
+		if rows := b.TrimTrailing(); rows != 19 || b.Rows() != 19 {
+			t.Fatal("wrong amount of rows", rows, b.Rows())
+		}
+		for row := 0; row < 19; row++ {
+			if v, _ := b.Get(row, 0); v != (row%3 == 0) {
+				t.Fatal("wrong value at", row)
+			}
+		}
+		if len(b.Data(false)) != (19*b.Stride()+7)/8 {
+			t.Fatal("data must be reallocated", len(b.Data(false)))
+		}
+		if rows := b.TrimTrailing(); rows != 19 {
+			t.Fatal("trimming twice mustn't change anything", rows)
+		}
+	}
+
+	b := New(30, 2)
+	if rows := b.TrimTrailing(); rows != 0 || b.Rows() != 0 || len(b.Data(false)) != 0 {
+		t.Fatal("an empty table must be trimmed to 0 rows", rows)
+	}
+}
+
+func TestRowParityColumn(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 11), NewTS(10, 11), NewAligned(10, 11)} {
+		for i := 0; i < 110; i += 1 + i%4 {
+			b.Set(i/11, i%11, true)
+		}
+		p := b.RowParityColumn()
+		if p.Rows() != 10 || p.Columns() != 1 {
+			t.Fatal("wrong dimensions")
+		}
+		for row := 0; row < 10; row++ {
+			n := 0
+			for column := 0; column < 11; column++ {
+				if v, _ := b.Get(row, column); v {
+					n++
+				}
+			}
+			if v, _ := p.Get(row, 0); v != (n%2 == 1) {
+				t.Fatal("wrong parity at", row)
+			}
+		}
+	}
+}
+
+func TestFindRow(t *testing.T) {
+	for _, b := range []Bitmaptable{New(8, 3), NewTS(8, 3), NewAligned(8, 3)} {
+		for row := 0; row < 8; row++ {
+			b.Set(row, 0, row >= 2)
+			b.Set(row, 1, row%2 == 0)
+		}
+		row, err := b.FindRow(func(values []bool) bool {
+			return values[0] && !values[1]
+		})
+		if err != nil || row != 3 {
+			t.Fatal("wrong row", row, err)
+		}
+		if row, _ := b.FindRow(func(values []bool) bool { return values[2] }); row != -1 {
+			t.Fatal("no row must be found", row)
+		}
+		calls := 0
+		b.FindRow(func(values []bool) bool {
+			calls++
+			return len(values) == 3
+		})
+		if calls != 1 {
+			t.Fatal("search must stop at the first match", calls)
+		}
+	}
+}
+
+func TestAppendTableFromData(t *testing.T) {
+	buf := []byte{0x01, 0xaa, 0xbb}
+	b, _ := NewFromData(2, 4, buf[:1])
+	other := New(2, 4)
+	other.Set(1, 3, true)
+	if err := b.AppendTable(other); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if buf[1] != 0xaa || buf[2] != 0xbb {
+		t.Fatal("bytes of the caller beyond the data mustn't be overwritten", buf)
+	}
+	if v, _ := b.Get(3, 3); !v || b.Count() != 2 {
+		t.Fatal("wrong appended rows")
+	}
+}
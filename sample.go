@@ -0,0 +1,58 @@
+package bitmaptable
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// SampleSetBits implements Bitmaptable.SampleSetBits
+func (b *bitmaptable) SampleSetBits(n int, rng *rand.Rand) []Coord {
+	if n <= 0 {
+		return []Coord{}
+	}
+	sample := make([]Coord, 0, n)
+	seen := 0
+	b.eachSetBit(func(row, column int) bool {
+		c := Coord{Row: row, Column: column}
+		if seen < n {
+			sample = append(sample, c)
+		} else if j := rng.Intn(seen + 1); j < n {
+			sample[j] = c
+		}
+		seen++
+		return true
+	})
+	return sample
+}
+
+// RangeSetBitsParallel implements Bitmaptable.RangeSetBitsParallel
+func (b *bitmaptable) RangeSetBitsParallel(workers int, fn func(row, column int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	n := len(b.bitmap)
+	if workers > n {
+		workers = n
+	}
+	if workers == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	per := (n + workers - 1) / workers
+	for start := 0; start < n; start += per {
+		end := start + per
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			b.eachSetBitIn(start, end, func(row, column int) bool {
+				fn(row, column)
+				return true
+			})
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,66 @@
+package bitmaptable
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSampleSetBits(t *testing.T) {
+	for _, b := range []Bitmaptable{New(100, 5), NewTS(100, 5)} {
+		var all []Coord
+		for i := 0; i < 500; i += 7 {
+			b.Set(i/5, i%5, true)
+			all = append(all, Coord{i / 5, i % 5})
+		}
+
+		s1 := b.SampleSetBits(10, rand.New(rand.NewSource(42)))
+		s2 := b.SampleSetBits(10, rand.New(rand.NewSource(42)))
+		if len(s1) != 10 || !reflect.DeepEqual(s1, s2) {
+			t.Fatal("sample isn't deterministic", s1, s2)
+		}
+		for _, c := range s1 {
+			if v, _ := b.Get(c.Row, c.Column); !v {
+				t.Fatal("sampled bit isn't set", c)
+			}
+		}
+
+		if s := b.SampleSetBits(1000, rand.New(rand.NewSource(1))); !reflect.DeepEqual(s, all) {
+			t.Fatal("all set bits must be returned")
+		}
+		if s := b.SampleSetBits(0, rand.New(rand.NewSource(1))); len(s) != 0 {
+			t.Fatal("empty sample must be returned")
+		}
+	}
+}
+
+func TestRangeSetBitsParallel(t *testing.T) {
+	for _, b := range []Bitmaptable{New(97, 7), NewTS(97, 7), NewAligned(97, 7)} {
+		var serial []Coord
+		for i := 0; i < 97*7; i += 5 {
+			b.Set(i/7, i%7, true)
+			serial = append(serial, Coord{i / 7, i % 7})
+		}
+
+		for _, workers := range []int{0, 1, 3, 1000} {
+			var mu sync.Mutex
+			var coords []Coord
+			b.RangeSetBitsParallel(workers, func(row, column int) {
+				mu.Lock()
+				coords = append(coords, Coord{row, column})
+				mu.Unlock()
+			})
+			sort.Slice(coords, func(i, j int) bool {
+				if coords[i].Row != coords[j].Row {
+					return coords[i].Row < coords[j].Row
+				}
+				return coords[i].Column < coords[j].Column
+			})
+			if !reflect.DeepEqual(coords, serial) {
+				t.Fatal("wrong set bits with workers", workers)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package bitmaptable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/boljen/go-bitmap"
+)
+
+// Version is the latest serialization format version. Unmarshal reads every
+// version up to and including this one.
+const Version byte = 1
+
+// headerSize is the size of the binary header: a version byte followed by the
+// rows and columns as big-endian 64-bit integers.
+const headerSize = 1 + 8 + 8
+
+// maxInt is the largest value of an int, which bounds the amount of cells of a
+// deserialized table.
+const maxInt = uint64(^uint(0) >> 1)
+
+// Marshal implements Bitmaptable.Marshal
+func (b *bitmaptable) Marshal() ([]byte, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	data := make([]byte, headerSize+(b.rows*b.columns+7)/8)
+	putHeader(data, b.rows, b.columns)
+	if b.stride == b.columns {
+		copy(data[headerSize:], b.bitmap)
+		return data, nil
+	}
+	dense := bitmap.Bitmap(data[headerSize:])
+	for row := 0; row < b.rows; row++ {
+		for column := 0; column < b.columns; column++ {
+			if b.bitmap.Get(row*b.stride + column) {
+				dense.Set(row*b.columns+column, true)
+			}
+		}
+	}
+	return data, nil
+}
+
+// Unmarshal deserializes a bitmap table from the binary format written by
+// Marshal. It returns ErrUnsupportedVersion for payloads written in a newer
+// format version than this package supports.
+func Unmarshal(data []byte) (Bitmaptable, error) {
+	rows, columns, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	// The header is validated against the data before allocating, so a
+	// forged header can't cause a huge allocation.
+	if len(data)-headerSize != (rows*columns+7)/8 {
+		return nil, ErrIllegalData
+	}
+	b := newNTS(rows, columns)
+	copy(b.bitmap, data[headerSize:])
+	return b, nil
+}
+
+// MarshalWithMeta implements Bitmaptable.MarshalWithMeta
+func (b *bitmaptable) MarshalWithMeta(meta []byte) ([]byte, error) {
+	if uint64(len(meta)) > 1<<32-1 {
+		return nil, ErrOverflow
+	}
+	table, err := b.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4+len(meta)+len(table))
+	binary.BigEndian.PutUint32(data, uint32(len(meta)))
+	copy(data[4:], meta)
+	copy(data[4+len(meta):], table)
+	return data, nil
+}
+
+// UnmarshalWithMeta deserializes a table and its metadata from the format
+// written by MarshalWithMeta. The returned metadata is a copy.
+func UnmarshalWithMeta(data []byte) (Bitmaptable, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, ErrIllegalData
+	}
+	n := uint64(binary.BigEndian.Uint32(data))
+	if n > uint64(len(data)-4) {
+		return nil, nil, ErrIllegalData
+	}
+	b, err := Unmarshal(data[4+n:])
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := make([]byte, n)
+	copy(meta, data[4:])
+	return b, meta, nil
+}
+
+// UnmarshalColumns deserializes only the provided columns, in the provided
+// order, of a table in the format of Marshal, without materializing the other
+// columns.
+func UnmarshalColumns(data []byte, columns []int) (Bitmaptable, error) {
+	rows, width, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)-headerSize != (rows*width+7)/8 {
+		return nil, ErrIllegalData
+	}
+	for _, column := range columns {
+		if column < 0 || column >= width {
+			return nil, ErrIllegalIndex
+		}
+	}
+	src := bitmap.Bitmap(data[headerSize:])
+	b := newNTS(rows, len(columns))
+	for row := 0; row < rows; row++ {
+		for i, column := range columns {
+			if src.Get(row*width + column) {
+				b.bitmap.Set(row*b.stride+i, true)
+			}
+		}
+	}
+	return b, nil
+}
+
+// writeBinary writes the table in the format of Marshal to w.
+func (b *bitmaptable) writeBinary(w io.Writer) error {
+	if b.closed {
+		return ErrClosed
+	}
+	if b.stride != b.columns {
+		data, _ := b.Marshal()
+		_, err := w.Write(data)
+		return err
+	}
+	var header [headerSize]byte
+	putHeader(header[:], b.rows, b.columns)
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b.bitmap[:(b.rows*b.columns+7)/8])
+	return err
+}
+
+// maxStreamData is the largest amount of data bytes of a table read from a
+// stream, which matches the largest frame of ReadFramed. It keeps a corrupt
+// header from causing a huge allocation before any data has been read.
+const maxStreamData = 1<<32 - 1 - headerSize
+
+// readBinary reads a table in the format of Marshal from r. Tables of more
+// than maxStreamData data bytes return ErrIllegalData.
+func readBinary(r io.Reader) (*bitmaptable, error) {
+	rows, columns, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if size := (rows*columns + 7) / 8; size > maxStreamData {
+		return nil, fmt.Errorf("%w: %d data bytes exceed the limit of %d", ErrIllegalData, size, maxStreamData)
+	}
+	b := newNTS(rows, columns)
+	if _, err := io.ReadFull(r, b.bitmap); err != nil {
+		return nil, readError(err)
+	}
+	return b, nil
+}
+
+// ReadHeader reads only the header of a table in the format of Marshal from r
+// and returns its dimensions and the amount of data bytes that follow it.
+func ReadHeader(r io.Reader) (rows, columns int, dataBytes int, err error) {
+	rows, columns, err = readHeader(r)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return rows, columns, (rows*columns + 7) / 8, nil
+}
+
+// readHeader reads and parses the header of the format of Marshal from r.
+func readHeader(r io.Reader) (rows, columns int, err error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:1]); err != nil {
+		return 0, 0, readError(err)
+	}
+	if _, _, err := parseHeader(header[:1]); err != ErrIllegalData {
+		return 0, 0, err
+	}
+	if _, err := io.ReadFull(r, header[1:]); err != nil {
+		return 0, 0, readError(err)
+	}
+	return parseHeader(header[:])
+}
+
+// readError translates errors of truncated input into ErrIllegalData.
+func readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrIllegalData
+	}
+	return err
+}
+
+func putHeader(data []byte, rows, columns int) {
+	data[0] = Version
+	binary.BigEndian.PutUint64(data[1:], uint64(rows))
+	binary.BigEndian.PutUint64(data[9:], uint64(columns))
+}
+
+func parseHeader(data []byte) (rows, columns int, err error) {
+	if len(data) < 1 {
+		return 0, 0, ErrIllegalData
+	}
+	if data[0] == 0 || data[0] > Version {
+		return 0, 0, fmt.Errorf("%w: got version %d, supported up to version %d", ErrUnsupportedVersion, data[0], Version)
+	}
+	if len(data) < headerSize {
+		return 0, 0, ErrIllegalData
+	}
+	r := binary.BigEndian.Uint64(data[1:])
+	c := binary.BigEndian.Uint64(data[9:])
+	if r > 1<<62 || c > 1<<62 || (c != 0 && r > (1<<62)/c) || r*c > maxInt {
+		return 0, 0, ErrIllegalData
+	}
+	return int(r), int(c), nil
+}
+
+// EstimateCompressedSize implements Bitmaptable.EstimateCompressedSize
+//
+// The data is encoded as runs of identical bytes, each run taking a varint of
+// its length followed by the byte value.
+func (b *bitmaptable) EstimateCompressedSize() int {
+	size := headerSize
+	var buf [binary.MaxVarintLen64]byte
+	for i := 0; i < len(b.bitmap); {
+		j := i + 1
+		for j < len(b.bitmap) && b.bitmap[j] == b.bitmap[i] {
+			j++
+		}
+		size += binary.PutUvarint(buf[:], uint64(j-i)) + 1
+		i = j
+	}
+	return size
+}
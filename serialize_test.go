@@ -0,0 +1,181 @@
+package bitmaptable
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5)} {
+		b.Set(0, 0, true)
+		b.Set(3, 4, true)
+		b.Set(9, 4, true)
+
+		data, err := b.Marshal()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if data[0] != Version || len(data) != headerSize+7 {
+			t.Fatal("wrong header")
+		}
+
+		u, err := Unmarshal(data)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if u.Rows() != 10 || u.Columns() != 5 || !bytes.Equal(u.Data(false), b.Data(false)) {
+			t.Fatal("wrong round trip")
+		}
+
+		if _, err := Unmarshal(data[:len(data)-1]); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned")
+		}
+		if _, err := Unmarshal(data[:5]); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned")
+		}
+		if _, err := Unmarshal(nil); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned")
+		}
+	}
+}
+
+func TestUnmarshalVersion(t *testing.T) {
+	v1 := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 3, 0x21}
+	b, err := Unmarshal(v1)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if v, _ := b.Get(0, 0); !v {
+		t.Fatal("wrong value")
+	}
+	if v, _ := b.Get(1, 2); !v {
+		t.Fatal("wrong value")
+	}
+
+	huge := []byte{1, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0}
+	if _, err := Unmarshal(huge); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned for a forged header", err)
+	}
+
+	v99 := append([]byte{99}, v1[1:]...)
+	if _, err := Unmarshal(v99); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatal("unsupported version must be returned", err)
+	}
+}
+
+func TestEstimateCompressedSize(t *testing.T) {
+	// The data of sparse holds runs of 12 zero bytes, byte 12, 863 zero
+	// bytes, byte 876 and 373 zero bytes, of which the lengths take 1, 1, 2,
+	// 1 and 2 bytes as varints.
+	sparse := New(1000, 10)
+	sparse.Set(10, 3, true)
+	sparse.Set(700, 9, true)
+
+	full := NewTS(8, 8)
+	for i := 0; i < 64; i++ {
+		full.Set(i/8, i%8, true)
+	}
+	full.Set(7, 7, false)
+
+	for _, c := range []struct {
+		b    Bitmaptable
+		size int
+	}{
+		{sparse, headerSize + 12},
+		{full, headerSize + 4},
+		{New(0, 5), headerSize},
+	} {
+		if size := c.b.EstimateCompressedSize(); size != c.size {
+			t.Fatal("wrong size", size, c.size)
+		}
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	data, _ := New(10, 5).Marshal()
+	r := bytes.NewReader(data)
+	rows, columns, dataBytes, err := ReadHeader(r)
+	if err != nil || rows != 10 || columns != 5 || dataBytes != 7 {
+		t.Fatal("wrong header", rows, columns, dataBytes, err)
+	}
+	if r.Len() != 7 {
+		t.Fatal("only the header must be consumed")
+	}
+
+	if _, _, _, err := ReadHeader(bytes.NewReader(data[:headerSize-1])); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned", err)
+	}
+	if _, _, _, err := ReadHeader(bytes.NewReader(nil)); err != ErrIllegalData {
+		t.Fatal("illegal data must be returned", err)
+	}
+	if _, _, _, err := ReadHeader(bytes.NewReader([]byte{99})); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatal("unsupported version must be returned", err)
+	}
+}
+
+func TestUnmarshalColumns(t *testing.T) {
+	for _, b := range []Bitmaptable{New(7, 40), NewTS(7, 40), NewAligned(7, 40)} {
+		for i := 0; i < 7*40; i += 3 {
+			b.Set(i/40, i%40, true)
+		}
+		data, _ := b.Marshal()
+
+		r, err := UnmarshalColumns(data, []int{33, 2})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if r.Rows() != 7 || r.Columns() != 2 {
+			t.Fatal("wrong dimensions", r.Rows(), r.Columns())
+		}
+		for row := 0; row < 7; row++ {
+			for i, column := range []int{33, 2} {
+				want, _ := b.Get(row, column)
+				if v, _ := r.Get(row, i); v != want {
+					t.Fatal("wrong value at", row, column)
+				}
+			}
+		}
+
+		if _, err := UnmarshalColumns(data, []int{40}); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := UnmarshalColumns(data[:len(data)-1], []int{0}); err != ErrIllegalData {
+			t.Fatal("illegal data must be returned")
+		}
+	}
+}
+
+func TestMarshalWithMeta(t *testing.T) {
+	for _, b := range []Bitmaptable{New(9, 5), NewTS(9, 5), NewAligned(9, 5)} {
+		b.Set(0, 4, true)
+		b.Set(8, 0, true)
+		for _, meta := range [][]byte{[]byte("schema=v2"), {}} {
+			data, err := b.MarshalWithMeta(meta)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			r, m, err := UnmarshalWithMeta(data)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !Equal(r, b) {
+				t.Fatal("wrong round trip")
+			}
+			if !bytes.Equal(m, meta) {
+				t.Fatal("wrong metadata", m)
+			}
+
+			if _, _, err := UnmarshalWithMeta(data[:len(data)-1]); err != ErrIllegalData {
+				t.Fatal("illegal data must be returned")
+			}
+			if _, _, err := UnmarshalWithMeta(data[:3]); err != ErrIllegalData {
+				t.Fatal("illegal data must be returned")
+			}
+			data[0] = 0xff
+			if _, _, err := UnmarshalWithMeta(data); err != ErrIllegalData {
+				t.Fatal("illegal data must be returned for a wrong length")
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package bitmaptable
+
+import "math/bits"
+
+// StateTable stores one of a fixed list of named states per row, using as few
+// bits per row as are needed to distinguish the states.
+type StateTable struct {
+	table  Bitmaptable
+	names  []string
+	states map[string]uint64
+}
+
+// NewStateTable creates a new StateTable of the provided amount of rows.
+// Every row starts out in the first state.
+func NewStateTable(rows int, names ...string) (*StateTable, error) {
+	if len(names) == 0 {
+		return nil, ErrStates
+	}
+	states := make(map[string]uint64, len(names))
+	for i, name := range names {
+		if _, ok := states[name]; ok {
+			return nil, ErrStates
+		}
+		states[name] = uint64(i)
+	}
+	width := bits.Len(uint(len(names) - 1))
+	if width == 0 {
+		width = 1
+	}
+	return &StateTable{
+		table:  New(rows, width),
+		names:  append([]string(nil), names...),
+		states: states,
+	}, nil
+}
+
+// Rows returns the amount of rows inside this state table.
+func (s *StateTable) Rows() int {
+	return s.table.Rows()
+}
+
+// Width returns the amount of bits used per row.
+func (s *StateTable) Width() int {
+	return s.table.Columns()
+}
+
+// SetState sets the state of the provided row.
+func (s *StateTable) SetState(row int, name string) error {
+	state, ok := s.states[name]
+	if !ok {
+		return ErrUnknownState
+	}
+	if row < 0 || row >= s.table.Rows() {
+		return ErrIllegalIndex
+	}
+	for column := 0; column < s.table.Columns(); column++ {
+		s.table.Set(row, column, state&(1<<uint(column)) != 0)
+	}
+	return nil
+}
+
+// GetState gets the state of the provided row.
+func (s *StateTable) GetState(row int) (string, error) {
+	var state uint64
+	for column := 0; column < s.table.Columns(); column++ {
+		v, err := s.table.Get(row, column)
+		if err != nil {
+			return "", err
+		}
+		if v {
+			state |= 1 << uint(column)
+		}
+	}
+	if state >= uint64(len(s.names)) {
+		return "", ErrUnknownState
+	}
+	return s.names[state], nil
+}
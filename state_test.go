@@ -0,0 +1,62 @@
+package bitmaptable
+
+import "testing"
+
+func TestStateTable(t *testing.T) {
+	names := []string{"new", "active", "suspended", "closed", "deleted"}
+	s, err := NewStateTable(10, names...)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if s.Rows() != 10 || s.Width() != 3 {
+		t.Fatal("wrong configuration")
+	}
+	if state, _ := s.GetState(9); state != "new" {
+		t.Fatal("rows must start in the first state")
+	}
+	for row, name := range names {
+		if err := s.SetState(row, name); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+	for row, name := range names {
+		if state, err := s.GetState(row); err != nil || state != name {
+			t.Fatal("wrong state for row", row, state)
+		}
+	}
+
+	if err := s.SetState(0, "archived"); err != ErrUnknownState {
+		t.Fatal("unknown state must be returned")
+	}
+	if err := s.SetState(10, "new"); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+	if _, err := s.GetState(10); err != ErrIllegalIndex {
+		t.Fatal("illegal index must be returned")
+	}
+
+	// Value 7 isn't a valid state for five names.
+	s.table.FlipRow(0)
+	if _, err := s.GetState(0); err != ErrUnknownState {
+		t.Fatal("unknown state must be returned")
+	}
+}
+
+func TestNewStateTable(t *testing.T) {
+	for n, width := range map[int]int{1: 1, 2: 1, 3: 2, 4: 2, 5: 3, 8: 3, 9: 4} {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = string(rune('a' + i))
+		}
+		s, err := NewStateTable(1, names...)
+		if err != nil || s.Width() != width {
+			t.Fatal("wrong width for", n, "states")
+		}
+	}
+	if _, err := NewStateTable(1); err != ErrStates {
+		t.Fatal("states error must be returned")
+	}
+	if _, err := NewStateTable(1, "a", "b", "a"); err != ErrStates {
+		t.Fatal("states error must be returned")
+	}
+}
@@ -0,0 +1,51 @@
+package bitmaptable
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TopRowsByPopcount implements Bitmaptable.TopRowsByPopcount
+//
+// A heap of n rows is kept while scanning, so this costs O(rows*log(n)).
+func (b *bitmaptable) TopRowsByPopcount(n int) []RowCount {
+	if n <= 0 {
+		return []RowCount{}
+	}
+	h := make(rowCountHeap, 0, n)
+	for row := 0; row < b.rows; row++ {
+		rc := RowCount{Row: row, Count: b.rowPopcount(row)}
+		if len(h) < n {
+			heap.Push(&h, rc)
+		} else if rowCountLess(h[0], rc) {
+			h[0] = rc
+			heap.Fix(&h, 0)
+		}
+	}
+	top := []RowCount(h)
+	sort.Slice(top, func(i, j int) bool { return rowCountLess(top[j], top[i]) })
+	return top
+}
+
+// rowCountLess returns whether a ranks below b: it has a lower count, or the
+// same count and a higher row.
+func rowCountLess(a, b RowCount) bool {
+	if a.Count != b.Count {
+		return a.Count < b.Count
+	}
+	return a.Row > b.Row
+}
+
+// rowCountHeap is a min-heap with the lowest ranking row on top.
+type rowCountHeap []RowCount
+
+func (h rowCountHeap) Len() int            { return len(h) }
+func (h rowCountHeap) Less(i, j int) bool  { return rowCountLess(h[i], h[j]) }
+func (h rowCountHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *rowCountHeap) Push(x interface{}) { *h = append(*h, x.(RowCount)) }
+func (h *rowCountHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,28 @@
+package bitmaptable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopRowsByPopcount(t *testing.T) {
+	for _, b := range []Bitmaptable{New(6, 4), NewTS(6, 4), NewAligned(6, 4)} {
+		// Popcounts per row: 1, 3, 0, 3, 4, 1
+		for _, c := range []Coord{{0, 2}, {1, 0}, {1, 1}, {1, 3}, {3, 0}, {3, 2}, {3, 3}, {4, 0}, {4, 1}, {4, 2}, {4, 3}, {5, 1}} {
+			b.Set(c.Row, c.Column, true)
+		}
+
+		if top := b.TopRowsByPopcount(3); !reflect.DeepEqual(top, []RowCount{{4, 4}, {1, 3}, {3, 3}}) {
+			t.Fatal("wrong top rows", top)
+		}
+		if top := b.TopRowsByPopcount(5); !reflect.DeepEqual(top, []RowCount{{4, 4}, {1, 3}, {3, 3}, {0, 1}, {5, 1}}) {
+			t.Fatal("wrong top rows", top)
+		}
+		if top := b.TopRowsByPopcount(10); len(top) != 6 || top[5] != (RowCount{2, 0}) {
+			t.Fatal("wrong top rows", top)
+		}
+		if top := b.TopRowsByPopcount(0); len(top) != 0 {
+			t.Fatal("no rows must be returned")
+		}
+	}
+}
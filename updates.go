@@ -0,0 +1,32 @@
+package bitmaptable
+
+import "context"
+
+// Update is a single cell assignment consumed by ConsumeUpdates.
+type Update struct {
+	Row    int
+	Column int
+	Value  bool
+}
+
+// ConsumeUpdates implements Bitmaptable.ConsumeUpdates
+func (b *bitmaptable) ConsumeUpdates(ctx context.Context, ch <-chan Update) error {
+	return consumeUpdates(ctx, ch, b.Set)
+}
+
+// consumeUpdates applies the updates received from ch with set.
+func consumeUpdates(ctx context.Context, ch <-chan Update, set func(row, column int, value bool) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := set(u.Row, u.Column, u.Value); err != nil {
+				return err
+			}
+		}
+	}
+}
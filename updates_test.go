@@ -0,0 +1,46 @@
+package bitmaptable
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConsumeUpdates(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 4), NewTS(10, 4), NewAligned(10, 4)} {
+		updates := []Update{{0, 0, true}, {3, 2, true}, {9, 3, true}, {3, 2, false}, {5, 1, true}}
+		ch := make(chan Update)
+		go func() {
+			for _, u := range updates {
+				ch <- u
+			}
+			close(ch)
+		}()
+		if err := b.ConsumeUpdates(context.Background(), ch); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		for _, c := range []Coord{{0, 0}, {9, 3}, {5, 1}} {
+			if v, _ := b.Get(c.Row, c.Column); !v {
+				t.Fatal("update must be applied", c)
+			}
+		}
+		if b.Count() != 3 {
+			t.Fatal("wrong amount of set cells", b.Count())
+		}
+
+		ch = make(chan Update, 2)
+		ch <- Update{1, 1, true}
+		ch <- Update{10, 0, true}
+		if err := b.ConsumeUpdates(context.Background(), ch); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned", err)
+		}
+		if v, _ := b.Get(1, 1); !v {
+			t.Fatal("updates before the failing one must be applied")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := b.ConsumeUpdates(ctx, make(chan Update)); err != context.Canceled {
+			t.Fatal("context error must be returned", err)
+		}
+	}
+}
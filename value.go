@@ -0,0 +1,40 @@
+package bitmaptable
+
+// Get2Bit implements Bitmaptable.Get2Bit
+func (b *bitmaptable) Get2Bit(row, startColumn int) (uint8, error) {
+	if err := b.check(row, startColumn+1); err != nil {
+		return 0, err
+	}
+	if err := b.check(row, startColumn); err != nil {
+		return 0, err
+	}
+	var v uint8
+	i := row*b.stride + startColumn
+	if b.bitmap.Get(i) {
+		v |= 1
+	}
+	if b.bitmap.Get(i + 1) {
+		v |= 2
+	}
+	return v, nil
+}
+
+// Set2Bit implements Bitmaptable.Set2Bit
+func (b *bitmaptable) Set2Bit(row, startColumn int, v uint8) error {
+	if err := b.writable(); err != nil {
+		return err
+	}
+	if err := b.check(row, startColumn+1); err != nil {
+		return err
+	}
+	if err := b.check(row, startColumn); err != nil {
+		return err
+	}
+	if v > 3 {
+		return ErrOverflow
+	}
+	i := row*b.stride + startColumn
+	b.bitmap.Set(i, v&1 != 0)
+	b.bitmap.Set(i+1, v&2 != 0)
+	return nil
+}
@@ -0,0 +1,36 @@
+package bitmaptable
+
+import "testing"
+
+func TestGetSet2Bit(t *testing.T) {
+	for _, b := range []Bitmaptable{New(10, 5), NewTS(10, 5), NewAligned(10, 5)} {
+		// Row 3 starts at bit 15, so column 0 straddles a byte boundary.
+		for _, c := range []Coord{{0, 0}, {0, 3}, {3, 0}, {9, 3}} {
+			for v := uint8(0); v < 4; v++ {
+				if err := b.Set2Bit(c.Row, c.Column, v); err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				if r, err := b.Get2Bit(c.Row, c.Column); err != nil || r != v {
+					t.Fatal("wrong value at", c, r, v)
+				}
+				lo, _ := b.Get(c.Row, c.Column)
+				hi, _ := b.Get(c.Row, c.Column+1)
+				if lo != (v&1 != 0) || hi != (v&2 != 0) {
+					t.Fatal("wrong columns at", c)
+				}
+			}
+		}
+		if err := b.Set2Bit(0, 0, 4); err != ErrOverflow {
+			t.Fatal("overflow must be returned")
+		}
+		if err := b.Set2Bit(0, 4, 1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.Get2Bit(10, 0); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+		if _, err := b.Get2Bit(0, -1); err != ErrIllegalIndex {
+			t.Fatal("illegal index must be returned")
+		}
+	}
+}